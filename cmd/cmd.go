@@ -0,0 +1,57 @@
+// Package cmd holds the subcommands of the gofi binary. They all read the
+// same .env configuration and connect through database.NewDatabase.
+package cmd
+
+import (
+	"fmt"
+	"gofi/database"
+	"log"
+)
+
+const usage = `usage: gofi [command]
+
+commands:
+  serve                 start the HTTP server, the default command
+  migrate <command>     apply, roll back or create migrations
+  seed                  add demo projects and time entries
+  user create           create a user with a role
+  user reset-password   set a new password and sign the user out
+  session purge         delete the expired sessions
+  routes                print the route table`
+
+// Execute runs the subcommand named by the arguments, without the program
+// name. The server is started when there is none.
+func Execute(args []string) {
+	if len(args) == 0 {
+		serve()
+		return
+	}
+
+	switch args[0] {
+	case "serve":
+		serve()
+	case "migrate":
+		migrate(args[1:])
+	case "seed":
+		seed()
+	case "user":
+		user(args[1:])
+	case "session":
+		session(args[1:])
+	case "routes":
+		printRoutes()
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func openDatabase() *database.Database {
+	db, err := database.NewDatabase()
+	if err != nil {
+		log.Fatalf("error opening database: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"gofi/database"
+	"gofi/database/migrations"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// migrationDir is where `gofi migrate create` writes new migrations, they are
+// embedded in the binary at build time.
+const migrationDir = "./database/migrations"
+
+const migrateUsage = `usage: gofi migrate <command>
+
+commands:
+  up             apply the pending migrations
+  down [n|all]   roll back the last n migrations, 1 by default
+  status         list the migrations and whether they are applied
+  create <name>  write empty up and down scripts for a new migration`
+
+// migrate runs `gofi migrate <command>`.
+func migrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			log.Fatal(migrateUsage)
+		}
+
+		up, down, err := migrations.Create(migrationDir, args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("created %s and %s", up, down)
+		return
+	}
+
+	db := openDatabase()
+	defer db.Close()
+
+	migrator, err := migrations.New(db.GetDB())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Printf("applied %06d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			log.Printf("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = int(^uint(0) >> 1)
+			} else if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatal(migrateUsage)
+			}
+		}
+
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			log.Printf("rolled back %06d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(rolledBack) == 0 {
+			log.Printf("no applied migrations")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			status, appliedAt := "pending", ""
+			if s.AppliedAt != nil {
+				status, appliedAt = "applied", s.AppliedAt.Format(time.DateTime)
+			}
+			if s.Modified {
+				status = "modified"
+			}
+			if s.Missing {
+				status = "missing"
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		w.Flush()
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
+
+// autoMigrate applies the pending migrations before the server starts.
+func autoMigrate(db *database.Database) {
+	migrator, err := migrations.New(db.GetDB())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, m := range applied {
+		log.Printf("applied %06d_%s", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("error migrating database: %v", err)
+	}
+}
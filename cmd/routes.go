@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// unconnected is a connector that refuses to connect, the route table only
+// needs the handlers registered, not a database to serve them from.
+type unconnected struct{}
+
+func (unconnected) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("gofi routes does not connect to the database")
+}
+
+func (unconnected) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// printRoutes runs `gofi routes`.
+func printRoutes() {
+	db := sqlx.NewDb(sql.OpenDB(unconnected{}), "postgres")
+	defer db.Close()
+
+	app := newApp(db)
+
+	// HEAD routes are registered along with every GET route
+	var routes []fiber.Route
+	for _, route := range app.GetRoutes(true) {
+		if route.Method != http.MethodHead {
+			routes = append(routes, route)
+		}
+	}
+	slices.SortStableFunc(routes, func(a, b fiber.Route) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH")
+	for _, route := range routes {
+		fmt.Fprintf(w, "%s\t%s\n", route.Method, route.Path)
+	}
+	w.Flush()
+}
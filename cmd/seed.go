@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"context"
+	"gofi/database/seeds"
+	"log"
+)
+
+// seed runs `gofi seed`.
+func seed() {
+	db := openDatabase()
+	defer db.Close()
+
+	done, err := seeds.Run(context.Background(), db.GetDB())
+	for _, name := range done {
+		log.Printf("seeded %s", name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"gofi/config"
+	"gofi/jobs"
+	"gofi/middleware"
+	"gofi/pkg/scheduler"
+	"gofi/routes"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/helmet"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	port         = config.Env("APP_PORT", "8080")
+	dbname       = config.Env("DB_DATABASE", "db_example")
+	ratelimit, _ = strconv.Atoi(config.Env("APP_RATE_LIMIT", "100"))
+)
+
+// newApp builds the fiber app with its middleware and routes.
+func newApp(db *sqlx.DB) *fiber.App {
+	// fiber instance
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+
+	// use middleware
+	app.Use(cors.New(config.Cors()))
+	app.Use(compress.New())
+	app.Use(helmet.New())
+	app.Use(logger.New())
+	app.Use(limiter.New(limiter.Config{Max: ratelimit}))
+	app.Use(requestid.New())
+	app.Use(recover.New())
+
+	// static file
+	app.Static("/", "./public")
+
+	// initial routes
+	routes.Routes(db, app)
+
+	return app
+}
+
+// serve runs `gofi serve`.
+func serve() {
+	// database instance
+	db := openDatabase()
+	defer db.Close()
+	log.Printf("successfully connected to database %v", dbname)
+
+	if config.AutoMigrate() {
+		autoMigrate(db)
+	}
+
+	app := newApp(db.GetDB())
+
+	// background jobs
+	jobScheduler := scheduler.New(db.GetDB())
+	jobs.Jobs(db.GetDB(), jobScheduler)
+	if config.SchedulerEnabled() {
+		jobScheduler.Start()
+	}
+
+	// listen app
+	go func() {
+		if err := app.Listen(":" + port); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// graceful shutdown, let in-flight requests and jobs finish
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Printf("shutting down")
+
+	if err := app.Shutdown(); err != nil {
+		log.Printf("error shutting down server: %v", err)
+	}
+
+	jobScheduler.Stop()
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"gofi/database/repository"
+	"gofi/service"
+	"log"
+)
+
+const sessionUsage = `usage: gofi session <command>
+
+commands:
+  purge   delete the sessions that can no longer be used or refreshed`
+
+// session runs `gofi session <command>`.
+func session(args []string) {
+	if len(args) != 1 || args[0] != "purge" {
+		log.Fatal(sessionUsage)
+	}
+
+	db := openDatabase()
+	defer db.Close()
+
+	sessionRepo := repository.NewSessionRepository(db.GetDB())
+	twoFactorRepo := repository.NewTwoFactorRepository(db.GetDB())
+	oidcRepo := repository.NewOIDCRepository(db.GetDB())
+	passwordResetRepo := repository.NewPasswordResetRepository(db.GetDB())
+	cleanupService := service.NewCleanupService(sessionRepo, twoFactorRepo, oidcRepo, passwordResetRepo)
+
+	if err := cleanupService.PurgeExpiredSessions(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("expired sessions purged")
+}
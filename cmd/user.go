@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"gofi/config"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"log"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const userUsage = `usage: gofi user <command>
+
+commands:
+  create --fullname <name> --email <email> [--role <name>] [--password <password>]
+  reset-password --email <email> [--password <password>]
+
+a random password is generated and printed when --password is left out`
+
+// user runs `gofi user <command>`.
+func user(args []string) {
+	if len(args) == 0 {
+		log.Fatal(userUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		createUser(args[1:])
+	case "reset-password":
+		resetPassword(args[1:])
+	default:
+		log.Fatal(userUsage)
+	}
+}
+
+func newUserService(db *sqlx.DB) *service.UserService {
+	userRepo := repository.NewUserRepository(db)
+	lockoutRepo := repository.NewAccountLockoutRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	return service.NewUserService(userRepo, lockoutRepo, permissionRepo, config.Mailer(), config.AppURL()+"/v1/auth/verify-email")
+}
+
+// parseUserFlags parses the flags of a user command, exiting with the usage
+// when they are invalid.
+func parseUserFlags(name string, args []string, define func(fs *flag.FlagSet)) {
+	fs := flag.NewFlagSet("user "+name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, userUsage)
+	}
+	define(fs)
+
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		log.Fatal(userUsage)
+	}
+}
+
+// passwordOrGenerate returns the given password, or a random one after
+// printing it.
+func passwordOrGenerate(password string) string {
+	if password != "" {
+		return password
+	}
+
+	password, err := utils.GenerateToken(12)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("password: %s\n", password)
+
+	return password
+}
+
+func createUser(args []string) {
+	var fullname, email, role, password string
+	parseUserFlags("create", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&fullname, "fullname", "", "full name of the user")
+		fs.StringVar(&email, "email", "", "email of the user")
+		fs.StringVar(&role, "role", "User", "name of the role")
+		fs.StringVar(&password, "password", "", "password, generated when empty")
+	})
+
+	db := openDatabase()
+	defer db.Close()
+
+	ctx := context.Background()
+	roleService := service.NewRoleService(repository.NewRoleRepository(db.GetDB()))
+	userService := newUserService(db.GetDB())
+
+	record, err := roleService.GetRoleByName(ctx, role)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	password = passwordOrGenerate(password)
+
+	r := &entity.UserReq{
+		Fullname: fullname,
+		Email:    email,
+		Password: password,
+		RoleID:   record.ID,
+	}
+	if _, _, errors := utils.Validate(r, utils.Locales[0]); errors != nil {
+		for _, e := range errors {
+			log.Print(e.Message)
+		}
+		os.Exit(1)
+	}
+
+	created, err := userService.CreateUser(ctx, &entity.User{
+		Fullname: r.Fullname,
+		Email:    r.Email,
+		RoleID:   r.RoleID,
+		IsActive: true,
+	}, r.Password)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("created user %s <%s> with role %s", created.ID, created.Email, record.Name)
+}
+
+func resetPassword(args []string) {
+	var email, password string
+	parseUserFlags("reset-password", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&email, "email", "", "email of the user")
+		fs.StringVar(&password, "password", "", "new password, generated when empty")
+	})
+
+	if email == "" {
+		log.Fatal(userUsage)
+	}
+
+	db := openDatabase()
+	defer db.Close()
+
+	ctx := context.Background()
+	userService := newUserService(db.GetDB())
+
+	record, err := userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	password = passwordOrGenerate(password)
+	if len(password) < 8 {
+		log.Fatal("password must be at least 8 characters long")
+	}
+
+	if err := userService.SetPassword(ctx, record.ID, password); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("password of %s has been reset, their sessions are revoked", record.Email)
+}
@@ -0,0 +1,19 @@
+package config
+
+import "strings"
+
+// AppURL is the public base URL of the API, used to build links sent by email.
+func AppURL() string {
+	return strings.TrimSuffix(Env("APP_URL", "http://localhost:"+Env("APP_PORT", "8000")), "/")
+}
+
+// AppName is the display name of the API, e.g. shown by authenticator apps.
+func AppName() string {
+	return Env("APP_NAME", "gofi")
+}
+
+// PasswordResetURL is the page a password reset email links to. The token
+// is appended to it as the "token" query parameter.
+func PasswordResetURL() string {
+	return Env("PASSWORD_RESET_URL", AppURL()+"/v1/auth/reset-password")
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"log"
+	"time"
+)
+
+// SessionExpiresIn is how long the access token of a session stays valid.
+func SessionExpiresIn() time.Duration {
+	expiresIn, err := time.ParseDuration(Env("SESSION_EXPIRES_IN", "15m"))
+	if err != nil {
+		log.Fatalf("invalid SESSION_EXPIRES_IN: %v", err)
+	}
+
+	return expiresIn
+}
+
+// RefreshExpiresIn is how long the refresh token of a session stays valid.
+func RefreshExpiresIn() time.Duration {
+	expiresIn, err := time.ParseDuration(Env("REFRESH_EXPIRES_IN", "720h"))
+	if err != nil {
+		log.Fatalf("invalid REFRESH_EXPIRES_IN: %v", err)
+	}
+
+	return expiresIn
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"crypto/rand"
+	"log"
+	"sync"
+)
+
+var (
+	cursorSecret     []byte
+	cursorSecretOnce sync.Once
+)
+
+// CursorSecret is the key signing pagination cursors. Without CURSOR_SECRET a
+// random key is used, so cursors stop working on restart and are not shared
+// between replicas.
+func CursorSecret() []byte {
+	cursorSecretOnce.Do(func() {
+		if secret := Env("CURSOR_SECRET", ""); secret != "" {
+			cursorSecret = []byte(secret)
+			return
+		}
+
+		cursorSecret = make([]byte, 32)
+		if _, err := rand.Read(cursorSecret); err != nil {
+			log.Fatalf("error generating cursor secret: %v", err)
+		}
+		log.Printf("CURSOR_SECRET is not set, pagination cursors will not survive a restart")
+	})
+
+	return cursorSecret
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"gofi/pkg/jwt"
+	"log"
+	"strings"
+	"sync"
+)
+
+var (
+	jwtKeySet     *jwt.KeySet
+	jwtKeySetOnce sync.Once
+)
+
+// JWTKeySet loads the keys listed in JWT_KEYS as comma separated kid=path
+// pairs, once per process. It returns nil unless JWT_ENABLED is true, in
+// which case access tokens stay opaque.
+func JWTKeySet() *jwt.KeySet {
+	jwtKeySetOnce.Do(func() {
+		if Env("JWT_ENABLED", "false") == "true" {
+			jwtKeySet = loadJWTKeySet()
+		}
+	})
+
+	return jwtKeySet
+}
+
+func loadJWTKeySet() *jwt.KeySet {
+	var keys []*jwt.Key
+	for _, pair := range strings.Split(Env("JWT_KEYS", ""), ",") {
+		kid, path, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			log.Fatalf("invalid JWT_KEYS entry %q, expected kid=path", pair)
+		}
+
+		key, err := jwt.LoadKey(kid, path)
+		if err != nil {
+			log.Fatalf("invalid JWT_KEYS: %v", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	// new tokens are signed with the first key unless told otherwise
+	signingKid := Env("JWT_SIGNING_KID", keys[0].ID)
+
+	keySet, err := jwt.NewKeySet(Env("JWT_ISSUER", Env("APP_NAME", "gofi")), signingKid, keys...)
+	if err != nil {
+		log.Fatalf("invalid JWT configuration: %v", err)
+	}
+
+	return keySet
+}
@@ -0,0 +1,20 @@
+package config
+
+import "gofi/pkg/mailer"
+
+// Mailer sends email through MAIL_HOST when it is set and falls back to
+// writing every email to the application log otherwise.
+func Mailer() mailer.Mailer {
+	host := Env("MAIL_HOST", "")
+	if host == "" {
+		return mailer.NewLogMailer()
+	}
+
+	return mailer.NewSMTPMailer(mailer.SMTPConfig{
+		Host:     host,
+		Port:     Env("MAIL_PORT", "587"),
+		Username: Env("MAIL_USERNAME", ""),
+		Password: Env("MAIL_PASSWORD", ""),
+		From:     Env("MAIL_FROM", "no-reply@localhost"),
+	})
+}
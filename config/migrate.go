@@ -0,0 +1,7 @@
+package config
+
+// AutoMigrate reports whether the server applies pending migrations when it
+// starts, instead of leaving it to `gofi migrate up`.
+func AutoMigrate() bool {
+	return Env("DB_AUTO_MIGRATE", "false") == "true"
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"gofi/pkg/constant"
+	"gofi/pkg/oidc"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// OIDCProvider returns the single sign-on provider configured by the OIDC_*
+// variables. It returns nil unless OIDC_ENABLED is true.
+func OIDCProvider() *oidc.Provider {
+	if Env("OIDC_ENABLED", "false") != "true" {
+		return nil
+	}
+
+	cfg := oidc.Config{
+		Issuer:       Env("OIDC_ISSUER", ""),
+		ClientID:     Env("OIDC_CLIENT_ID", ""),
+		ClientSecret: Env("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:  Env("OIDC_REDIRECT_URL", AppURL()+"/v1/auth/oidc/callback"),
+		Scopes:       strings.Fields(Env("OIDC_SCOPES", "openid email profile")),
+	}
+
+	if cfg.Issuer == "" || cfg.ClientID == "" {
+		log.Fatal("OIDC_ISSUER and OIDC_CLIENT_ID are required when OIDC_ENABLED is true")
+	}
+
+	return oidc.NewProvider(cfg)
+}
+
+// OIDCDefaultRoleID is the role given to users provisioned on their first
+// single sign-on.
+func OIDCDefaultRoleID() uuid.UUID {
+	id, err := uuid.Parse(Env("OIDC_DEFAULT_ROLE_ID", constant.RoleUser))
+	if err != nil {
+		log.Fatalf("invalid OIDC_DEFAULT_ROLE_ID: %v", err)
+	}
+
+	return id
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"log"
+	"time"
+)
+
+// SchedulerEnabled reports whether this instance runs the background jobs.
+// Replicas coordinate through advisory locks, so it can stay on everywhere.
+func SchedulerEnabled() bool {
+	return Env("SCHEDULER_ENABLED", "true") == "true"
+}
+
+// PurgeInterval is how often expired sessions and tokens are deleted.
+func PurgeInterval() time.Duration {
+	interval, err := time.ParseDuration(Env("PURGE_INTERVAL", "1h"))
+	if err != nil || interval <= 0 {
+		log.Fatalf("invalid PURGE_INTERVAL: %q", Env("PURGE_INTERVAL", "1h"))
+	}
+
+	return interval
+}
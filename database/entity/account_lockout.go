@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AccountLockout struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
+	FailedLoginCount int        `json:"failed_login_count" db:"failed_login_count"`
+	LockedUntil      time.Time  `json:"locked_until" db:"locked_until"`
+	UnlockedAt       *time.Time `json:"unlocked_at" db:"unlocked_at"`
+	UnlockedBy       *uuid.UUID `json:"unlocked_by" db:"unlocked_by"`
+}
+
+type AccountLockoutRes struct {
+	ID               uuid.UUID  `json:"id"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UserID           uuid.UUID  `json:"user_id"`
+	FailedLoginCount int        `json:"failed_login_count"`
+	LockedUntil      time.Time  `json:"locked_until"`
+	UnlockedAt       *time.Time `json:"unlocked_at"`
+	UnlockedBy       *uuid.UUID `json:"unlocked_by"`
+}
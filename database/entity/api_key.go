@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type APIKey struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at" db:"updated_at"`
+	UserID     uuid.UUID      `json:"user_id" db:"user_id"`
+	Name       string         `json:"name" db:"name"`
+	Prefix     string         `json:"prefix" db:"prefix"`
+	SecretHash string         `json:"-" db:"secret_hash"`
+	Scopes     pq.StringArray `json:"scopes" db:"scopes"`
+	ExpiredAt  *time.Time     `json:"expired_at" db:"expired_at"`
+	LastUsedAt *time.Time     `json:"last_used_at" db:"last_used_at"`
+
+	// Key is the full plain key, only known right after it is created. It
+	// is never stored.
+	Key string `json:"-" db:"-"`
+}
+
+type APIKeyReq struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes"`
+	ExpiredAt *time.Time `json:"expired_at"`
+}
+
+type APIKeyRes struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiredAt  *time.Time `json:"expired_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Key        string     `json:"key,omitempty"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+type SignInReq struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type SignUpReq struct {
+	Fullname string `json:"fullname" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+	Phone    string `json:"phone"`
+	Timezone string `json:"timezone"`
+}
+
+type RefreshReq struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type AuthRes struct {
+	Token            string    `json:"token"`
+	ExpiredAt        time.Time `json:"expired_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiredAt time.Time `json:"refresh_expired_at"`
+	User             UserRes   `json:"user"`
+}
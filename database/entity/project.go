@@ -17,9 +17,8 @@ type Project struct {
 }
 
 type ProjectReq struct {
-	OwnerID     uuid.UUID `json:"owner_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=1000"`
 }
 
 type ProjectRes struct {
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProjectMember struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ProjectID uuid.UUID `json:"project_id" db:"project_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+}
+
+type ProjectMemberReq struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type ProjectMemberRes struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ProjectID uuid.UUID `json:"project_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
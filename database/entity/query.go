@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryOptions narrows down a list, it is parsed from
+// ?page=&page_size=&sort=&filter[field]= and checked against the columns the
+// repository exposes for the entity.
+type QueryOptions struct {
+	Page     int
+	PageSize int
+	// Sort is a comma separated list of fields, a leading "-" sorts that
+	// field in descending order.
+	Sort    string
+	Filters map[string]string
+	// Keyset pages through the rows newest first by (created_at, id) instead
+	// of by offset, starting next to Cursor when it is set.
+	Keyset bool
+	Cursor *Cursor
+}
+
+func (o QueryOptions) Offset() int {
+	return (o.Page - 1) * o.PageSize
+}
+
+// Cursor is the key of the row a keyset page starts next to. Prev pages
+// towards the newer rows, before the key, instead of after it.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Prev      bool      `json:"prev,omitempty"`
+}
+
+// Page describes where a page of rows sits in the list. Total is only
+// counted for offset pagination, Next and Prev are only set for keyset
+// pagination when there are rows on that side.
+type Page struct {
+	Total int
+	Next  *Cursor
+	Prev  *Cursor
+}
@@ -7,21 +7,24 @@ import (
 )
 
 type Role struct {
-	ID        uuid.UUID  `json:"id" db:"id"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at" db:"deleted_at"`
-	Name      string     `json:"name" db:"name"`
+	ID         uuid.UUID  `json:"id" db:"id"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at" db:"deleted_at"`
+	Name       string     `json:"name" db:"name"`
+	Require2FA bool       `json:"require_2fa" db:"require_2fa"`
 }
 
 type RoleReq struct {
-	Name string `json:"name"`
+	Name       string `json:"name" validate:"required,max=255"`
+	Require2FA *bool  `json:"require_2fa"`
 }
 
 type RoleRes struct {
-	ID        uuid.UUID  `json:"id"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at"`
-	Name      string     `json:"name"`
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+	Name       string     `json:"name"`
+	Require2FA bool       `json:"require_2fa"`
 }
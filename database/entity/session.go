@@ -7,25 +7,48 @@ import (
 )
 
 type Session struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	UserID    uuid.UUID `json:"user_id" db:"user_id"`
-	Token     string    `json:"token" db:"token"`
-	ExpiredAt time.Time `json:"expired_at" db:"expired_at"`
+	ID               uuid.UUID  `json:"id" db:"id"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
+	Token            string     `json:"-" db:"token"`
+	ExpiredAt        time.Time  `json:"expired_at" db:"expired_at"`
+	RefreshTokenHash *string    `json:"-" db:"refresh_token_hash"`
+	RefreshExpiredAt *time.Time `json:"refresh_expired_at" db:"refresh_expired_at"`
+	FamilyID         uuid.UUID  `json:"family_id" db:"family_id"`
+	RevokedAt        *time.Time `json:"revoked_at" db:"revoked_at"`
+	IPAddress        *string    `json:"ip_address" db:"ip_address"`
+	UserAgent        *string    `json:"user_agent" db:"user_agent"`
+	Device           *string    `json:"device" db:"device"`
+
+	// RefreshToken is the plain refresh token, only known right after it is
+	// issued. It is never stored.
+	RefreshToken string `json:"-" db:"-"`
 }
 
 type SessionReq struct {
-	UserID    uuid.UUID `json:"user_id"`
-	Token     string    `json:"token"`
-	ExpiredAt time.Time `json:"expired_at"`
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	Token     string    `json:"token" validate:"required"`
+	ExpiredAt time.Time `json:"expired_at" validate:"required"`
 }
 
 type SessionRes struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    uuid.UUID `json:"user_id"`
-	Token     string    `json:"token"`
-	ExpiredAt time.Time `json:"expired_at"`
+	ID               uuid.UUID  `json:"id"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	UserID           uuid.UUID  `json:"user_id"`
+	ExpiredAt        time.Time  `json:"expired_at"`
+	RefreshExpiredAt *time.Time `json:"refresh_expired_at"`
+	FamilyID         uuid.UUID  `json:"family_id"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	IPAddress        *string    `json:"ip_address"`
+	UserAgent        *string    `json:"user_agent"`
+	Device           *string    `json:"device"`
+	Current          bool       `json:"current"`
+}
+
+// ClientInfo describes the client a session is opened from.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
 }
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TimeEntry struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at" db:"deleted_at"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	ProjectID   uuid.UUID  `json:"project_id" db:"project_id"`
+	StartedAt   time.Time  `json:"started_at" db:"started_at"`
+	EndedAt     *time.Time `json:"ended_at" db:"ended_at"`
+	Duration    int64      `json:"duration" db:"duration"`
+	Description string     `json:"description" db:"description"`
+	IsBillable  bool       `json:"is_billable" db:"is_billable"`
+}
+
+type TimeEntryReq struct {
+	ProjectID   uuid.UUID `json:"project_id" validate:"required"`
+	StartedAt   time.Time `json:"started_at" validate:"required"`
+	EndedAt     time.Time `json:"ended_at" validate:"required"`
+	Description string    `json:"description"`
+	IsBillable  *bool     `json:"is_billable"`
+}
+
+type TimeEntryRes struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	UserID      uuid.UUID  `json:"user_id"`
+	ProjectID   uuid.UUID  `json:"project_id"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at"`
+	Duration    int64      `json:"duration"`
+	Description string     `json:"description"`
+	IsBillable  bool       `json:"is_billable"`
+}
+
+type TimerReq struct {
+	ProjectID   uuid.UUID `json:"project_id" validate:"required"`
+	Description string    `json:"description"`
+	IsBillable  *bool     `json:"is_billable"`
+	Timezone    string    `json:"timezone"`
+}
+
+type TimerActionReq struct {
+	Timezone string `json:"timezone"`
+}
+
+type TimerRes struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	ProjectID   uuid.UUID  `json:"project_id"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at"`
+	Description string     `json:"description"`
+	IsBillable  bool       `json:"is_billable"`
+	IsRunning   bool       `json:"is_running"`
+	Elapsed     int64      `json:"elapsed"`
+	ElapsedText string     `json:"elapsed_text"`
+	Timezone    string     `json:"timezone"`
+}
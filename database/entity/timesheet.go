@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Timesheet struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Year        int        `json:"year" db:"year"`
+	Week        int        `json:"week" db:"week"`
+	Status      string     `json:"status" db:"status"`
+	SubmittedAt *time.Time `json:"submitted_at" db:"submitted_at"`
+	ReviewedAt  *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	ReviewerID  *uuid.UUID `json:"reviewer_id" db:"reviewer_id"`
+	Comment     *string    `json:"comment" db:"comment"`
+}
+
+type TimesheetSubmitReq struct {
+	Year int `json:"year" validate:"required"`
+	Week int `json:"week" validate:"required,min=1,max=53"`
+}
+
+type TimesheetReviewReq struct {
+	Comment string `json:"comment"`
+}
+
+type TimesheetRes struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Year        int        `json:"year"`
+	Week        int        `json:"week"`
+	Status      string     `json:"status"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
+	ReviewerID  *uuid.UUID `json:"reviewer_id"`
+	Comment     *string    `json:"comment"`
+}
@@ -7,31 +7,34 @@ import (
 )
 
 type User struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at" db:"deleted_at"`
-	Fullname    string     `json:"fullname" db:"fullname"`
-	Email       string     `json:"email" db:"email"`
-	Password    string     `json:"password" db:"password"`
-	Phone       string     `json:"phone" db:"phone"`
-	TokenVerify string     `json:"token_verify" db:"token_verify"`
-	IsActive    bool       `json:"is_active" db:"is_active"`
-	IsBlocked   bool       `json:"is_blocked" db:"is_blocked"`
-	RoleID      uuid.UUID  `json:"role_id" db:"role_id"`
-	UploadID    *uuid.UUID `json:"upload_id" db:"upload_id"`
+	ID               uuid.UUID  `json:"id" db:"id"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at" db:"deleted_at"`
+	Fullname         string     `json:"fullname" db:"fullname"`
+	Email            string     `json:"email" db:"email"`
+	Password         string     `json:"-" db:"password"`
+	Phone            *string    `json:"phone" db:"phone"`
+	TokenVerify      *string    `json:"-" db:"token_verify"`
+	IsActive         bool       `json:"is_active" db:"is_active"`
+	IsBlocked        bool       `json:"is_blocked" db:"is_blocked"`
+	RoleID           uuid.UUID  `json:"role_id" db:"role_id"`
+	UploadID         *uuid.UUID `json:"upload_id" db:"upload_id"`
+	Timezone         string     `json:"timezone" db:"timezone"`
+	FailedLoginCount int        `json:"failed_login_count" db:"failed_login_count"`
+	LockedUntil      *time.Time `json:"locked_until" db:"locked_until"`
 }
 
 type UserReq struct {
-	Fullname    string     `json:"fullname"`
-	Email       string     `json:"email"`
-	Password    string     `json:"password"`
-	Phone       string     `json:"phone"`
-	TokenVerify string     `json:"token_verify"`
-	IsActive    bool       `json:"is_active"`
-	IsBlocked   bool       `json:"is_blocked"`
-	RoleID      uuid.UUID  `json:"role_id"`
-	UploadID    *uuid.UUID `json:"upload_id"`
+	Fullname  string     `json:"fullname" validate:"required,max=255"`
+	Email     string     `json:"email" validate:"required,email,max=255"`
+	Password  string     `json:"password" validate:"omitempty,min=8"`
+	Phone     string     `json:"phone" validate:"omitempty,max=20"`
+	IsActive  *bool      `json:"is_active"`
+	IsBlocked *bool      `json:"is_blocked"`
+	RoleID    uuid.UUID  `json:"role_id" validate:"required"`
+	UploadID  *uuid.UUID `json:"upload_id"`
+	Timezone  string     `json:"timezone"`
 }
 
 type UserRes struct {
@@ -41,11 +44,11 @@ type UserRes struct {
 	DeletedAt   *time.Time `json:"deleted_at"`
 	Fullname    string     `json:"fullname"`
 	Email       string     `json:"email"`
-	Password    string     `json:"password"`
-	Phone       string     `json:"phone"`
-	TokenVerify string     `json:"token_verify"`
+	Phone       *string    `json:"phone"`
 	IsActive    bool       `json:"is_active"`
 	IsBlocked   bool       `json:"is_blocked"`
 	RoleID      uuid.UUID  `json:"role_id"`
 	UploadID    *uuid.UUID `json:"upload_id"`
+	Timezone    string     `json:"timezone"`
+	LockedUntil *time.Time `json:"locked_until"`
 }
@@ -1,6 +1,9 @@
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-SELECT * FROM pg_timezone_names;
-ALTER DATABASE "dev_dbtimesheet" SET timezone TO "Asia/Jakarta";
+DO $$
+BEGIN
+  EXECUTE format('ALTER DATABASE %I SET timezone TO %L', current_database(), 'Asia/Jakarta');
+END
+$$;
 
 CREATE TABLE "project" (
   "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
@@ -9,15 +12,15 @@ CREATE TABLE "project" (
   "deleted_at" timestamp,
   "owner_id" uuid NOT NULL,
   "name" varchar NOT NULL,
-  "description" text NOT NULL,
+  "description" text NOT NULL
 );
 
 CREATE INDEX idx_project_id ON "project" (id);
 CREATE INDEX idx_project_created_at ON "project" (created_at);
 CREATE INDEX idx_project_updated_at ON "project" (updated_at);
 CREATE INDEX idx_project_deleted_at ON "project" (deleted_at);
-CREATE INDEX idx_project_key_file ON "project" (owner_id);
-CREATE INDEX idx_project_filename ON "project" (name);
+CREATE INDEX idx_project_owner_id ON "project" (owner_id);
+CREATE INDEX idx_project_name ON "project" (name);
 
 CREATE TABLE "role" (
   "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
@@ -67,7 +70,6 @@ CREATE INDEX idx_user_is_blocked ON "user" (is_blocked);
 CREATE INDEX idx_user_role_id ON "user" (role_id);
 
 ALTER TABLE "user" ADD FOREIGN KEY ("role_id") REFERENCES "role" ("id");
-ALTER TABLE "user" ADD FOREIGN KEY ("upload_id") REFERENCES "upload" ("id");
 
 INSERT INTO "user" ("id","created_at","updated_at","deleted_at","fullname","email","password","phone","token_verify","is_active","is_blocked","role_id","upload_id") VALUES
 	 (uuid_generate_v4(),now(),now(),NULL,'Super Admin','super.admin@example.com','$argon2id$v=19$m=65536,t=3,p=2$hXwlaW+1NCwqKWDySLUk4g$ftx5ZLF5QjKLi50RW6qxPKZVDAPOvs6DxCY0L+GZz6A',NULL,NULL,true,false,'03ba326e-f9ed-410a-818f-eaa409c13622',NULL),
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_time_entry_id, idx_time_entry_created_at, idx_time_entry_updated_at, idx_time_entry_deleted_at, idx_time_entry_user_id, idx_time_entry_project_id, idx_time_entry_started_at, idx_time_entry_ended_at;
+
+DROP TABLE IF EXISTS public."time_entry";
@@ -0,0 +1,26 @@
+CREATE TABLE "time_entry" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "deleted_at" timestamp,
+  "user_id" uuid NOT NULL,
+  "project_id" uuid NOT NULL,
+  "started_at" timestamp NOT NULL,
+  "ended_at" timestamp NOT NULL,
+  "duration" bigint NOT NULL DEFAULT 0,
+  "description" text NOT NULL DEFAULT '',
+  "is_billable" bool NOT NULL DEFAULT false,
+  CONSTRAINT chk_time_entry_range CHECK ("ended_at" > "started_at")
+);
+
+CREATE INDEX idx_time_entry_id ON "time_entry" (id);
+CREATE INDEX idx_time_entry_created_at ON "time_entry" (created_at);
+CREATE INDEX idx_time_entry_updated_at ON "time_entry" (updated_at);
+CREATE INDEX idx_time_entry_deleted_at ON "time_entry" (deleted_at);
+CREATE INDEX idx_time_entry_user_id ON "time_entry" (user_id);
+CREATE INDEX idx_time_entry_project_id ON "time_entry" (project_id);
+CREATE INDEX idx_time_entry_started_at ON "time_entry" (started_at);
+CREATE INDEX idx_time_entry_ended_at ON "time_entry" (ended_at);
+
+ALTER TABLE "time_entry" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id");
+ALTER TABLE "time_entry" ADD FOREIGN KEY ("project_id") REFERENCES "project" ("id");
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_time_entry_running;
+
+UPDATE "time_entry" SET ended_at = now(), duration = EXTRACT(EPOCH FROM (now() - started_at))::bigint WHERE ended_at IS NULL;
+ALTER TABLE "time_entry" ALTER COLUMN "ended_at" SET NOT NULL;
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS "timezone";
@@ -0,0 +1,6 @@
+ALTER TABLE "user" ADD COLUMN "timezone" varchar NOT NULL DEFAULT 'Asia/Jakarta';
+
+ALTER TABLE "time_entry" ALTER COLUMN "ended_at" DROP NOT NULL;
+
+-- at most one running timer per user
+CREATE UNIQUE INDEX idx_time_entry_running ON "time_entry" (user_id) WHERE ended_at IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_timesheet_id, idx_timesheet_created_at, idx_timesheet_updated_at, idx_timesheet_user_id, idx_timesheet_status, idx_timesheet_user_week;
+
+DROP TABLE IF EXISTS public."timesheet";
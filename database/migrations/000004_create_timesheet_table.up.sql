@@ -0,0 +1,25 @@
+CREATE TABLE "timesheet" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "user_id" uuid NOT NULL,
+  "year" int NOT NULL,
+  "week" int NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'draft',
+  "submitted_at" timestamp,
+  "reviewed_at" timestamp,
+  "reviewer_id" uuid,
+  "comment" text,
+  CONSTRAINT chk_timesheet_week CHECK ("week" BETWEEN 1 AND 53),
+  CONSTRAINT chk_timesheet_status CHECK ("status" IN ('draft', 'submitted', 'approved', 'rejected'))
+);
+
+CREATE INDEX idx_timesheet_id ON "timesheet" (id);
+CREATE INDEX idx_timesheet_created_at ON "timesheet" (created_at);
+CREATE INDEX idx_timesheet_updated_at ON "timesheet" (updated_at);
+CREATE INDEX idx_timesheet_user_id ON "timesheet" (user_id);
+CREATE INDEX idx_timesheet_status ON "timesheet" (status);
+CREATE UNIQUE INDEX idx_timesheet_user_week ON "timesheet" (user_id, year, week);
+
+ALTER TABLE "timesheet" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id");
+ALTER TABLE "timesheet" ADD FOREIGN KEY ("reviewer_id") REFERENCES "user" ("id");
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_project_member_id, idx_project_member_created_at, idx_project_member_updated_at, idx_project_member_user_id, idx_project_member_project_user;
+
+DROP TABLE IF EXISTS public."project_member";
+
+ALTER TABLE "project" DROP CONSTRAINT IF EXISTS project_owner_id_fkey;
@@ -0,0 +1,17 @@
+CREATE TABLE "project_member" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "project_id" uuid NOT NULL,
+  "user_id" uuid NOT NULL
+);
+
+CREATE INDEX idx_project_member_id ON "project_member" (id);
+CREATE INDEX idx_project_member_created_at ON "project_member" (created_at);
+CREATE INDEX idx_project_member_updated_at ON "project_member" (updated_at);
+CREATE INDEX idx_project_member_user_id ON "project_member" (user_id);
+CREATE UNIQUE INDEX idx_project_member_project_user ON "project_member" (project_id, user_id);
+
+ALTER TABLE "project" ADD FOREIGN KEY ("owner_id") REFERENCES "user" ("id");
+ALTER TABLE "project_member" ADD FOREIGN KEY ("project_id") REFERENCES "project" ("id") ON DELETE CASCADE;
+ALTER TABLE "project_member" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id");
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_role_permission_id, idx_role_permission_created_at, idx_role_permission_updated_at, idx_role_permission_permission_id, idx_role_permission_role_permission;
+
+DROP TABLE IF EXISTS public."role_permission";
+
+DROP INDEX IF EXISTS idx_permission_id, idx_permission_created_at, idx_permission_updated_at, idx_permission_name;
+
+DROP TABLE IF EXISTS public."permission";
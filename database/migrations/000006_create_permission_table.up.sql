@@ -0,0 +1,53 @@
+CREATE TABLE "permission" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "name" varchar NOT NULL,
+  "description" text
+);
+
+CREATE INDEX idx_permission_id ON "permission" (id);
+CREATE INDEX idx_permission_created_at ON "permission" (created_at);
+CREATE INDEX idx_permission_updated_at ON "permission" (updated_at);
+CREATE UNIQUE INDEX idx_permission_name ON "permission" (name);
+
+CREATE TABLE "role_permission" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "role_id" uuid NOT NULL,
+  "permission_id" uuid NOT NULL
+);
+
+CREATE INDEX idx_role_permission_id ON "role_permission" (id);
+CREATE INDEX idx_role_permission_created_at ON "role_permission" (created_at);
+CREATE INDEX idx_role_permission_updated_at ON "role_permission" (updated_at);
+CREATE INDEX idx_role_permission_permission_id ON "role_permission" (permission_id);
+CREATE UNIQUE INDEX idx_role_permission_role_permission ON "role_permission" (role_id, permission_id);
+
+ALTER TABLE "role_permission" ADD FOREIGN KEY ("role_id") REFERENCES "role" ("id") ON DELETE CASCADE;
+ALTER TABLE "role_permission" ADD FOREIGN KEY ("permission_id") REFERENCES "permission" ("id") ON DELETE CASCADE;
+
+INSERT INTO "permission" ("name","description") VALUES
+	 ('role:read','List and view roles and their permissions'),
+	 ('role:manage','Create, update and delete roles and grant or revoke their permissions'),
+	 ('session:read','List and view sessions of every user'),
+	 ('session:manage','Create, update and delete sessions of every user'),
+	 ('project:write','Create projects'),
+	 ('timesheet:approve','Review, approve and reject submitted timesheets');
+
+-- Super Admin gets every permission
+INSERT INTO "role_permission" ("role_id","permission_id")
+SELECT '03ba326e-f9ed-410a-818f-eaa409c13622', id FROM "permission";
+
+-- Admin
+INSERT INTO "role_permission" ("role_id","permission_id")
+SELECT '9dc8b32b-aefe-44d3-bf19-6dc088d13174', id FROM "permission"
+WHERE name IN ('role:read', 'session:read', 'session:manage', 'project:write', 'timesheet:approve');
+
+-- User
+INSERT INTO "role_permission" ("role_id","permission_id")
+SELECT 'd7efa7e9-3c97-4217-a6bd-59e2eba53068', id FROM "permission"
+WHERE name IN ('project:write');
+
+-- Guest has no permission by default
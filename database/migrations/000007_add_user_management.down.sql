@@ -0,0 +1,3 @@
+DELETE FROM "permission" WHERE name='user:manage';
+
+DROP INDEX IF EXISTS idx_user_email_unique, idx_user_token_verify;
@@ -0,0 +1,10 @@
+-- emails are compared case-insensitively at sign-in, so keep them unique the same way
+CREATE UNIQUE INDEX idx_user_email_unique ON "user" (lower(email)) WHERE deleted_at IS NULL;
+CREATE INDEX idx_user_token_verify ON "user" (token_verify);
+
+INSERT INTO "permission" ("name","description") VALUES
+	 ('user:manage','List, create, update and delete users');
+
+INSERT INTO "role_permission" ("role_id","permission_id")
+SELECT r.id, p.id FROM "role" r, "permission" p
+WHERE r.id IN ('03ba326e-f9ed-410a-818f-eaa409c13622', '9dc8b32b-aefe-44d3-bf19-6dc088d13174') AND p.name='user:manage';
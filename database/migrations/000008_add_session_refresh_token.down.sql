@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_session_refresh_token_hash, idx_session_family_id;
+
+ALTER TABLE "session" DROP COLUMN IF EXISTS "refresh_token_hash";
+ALTER TABLE "session" DROP COLUMN IF EXISTS "refresh_expired_at";
+ALTER TABLE "session" DROP COLUMN IF EXISTS "family_id";
+ALTER TABLE "session" DROP COLUMN IF EXISTS "revoked_at";
@@ -0,0 +1,7 @@
+ALTER TABLE "session" ADD COLUMN "refresh_token_hash" text;
+ALTER TABLE "session" ADD COLUMN "refresh_expired_at" timestamp;
+ALTER TABLE "session" ADD COLUMN "family_id" uuid NOT NULL DEFAULT uuid_generate_v4();
+ALTER TABLE "session" ADD COLUMN "revoked_at" timestamp;
+
+CREATE UNIQUE INDEX idx_session_refresh_token_hash ON "session" (refresh_token_hash);
+CREATE INDEX idx_session_family_id ON "session" (family_id);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_mfa_challenge_id, idx_mfa_challenge_created_at, idx_mfa_challenge_updated_at, idx_mfa_challenge_user_id, idx_mfa_challenge_token_hash, idx_mfa_challenge_expired_at;
+
+DROP TABLE IF EXISTS public."mfa_challenge";
+
+DROP INDEX IF EXISTS idx_recovery_code_id, idx_recovery_code_created_at, idx_recovery_code_updated_at, idx_recovery_code_user_id;
+
+DROP TABLE IF EXISTS public."recovery_code";
+
+DROP INDEX IF EXISTS idx_user_two_factor_id, idx_user_two_factor_created_at, idx_user_two_factor_updated_at, idx_user_two_factor_user_id;
+
+DROP TABLE IF EXISTS public."user_two_factor";
+
+ALTER TABLE "role" DROP COLUMN IF EXISTS "require_2fa";
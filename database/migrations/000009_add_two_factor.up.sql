@@ -0,0 +1,54 @@
+ALTER TABLE "role" ADD COLUMN "require_2fa" boolean NOT NULL DEFAULT false;
+
+-- roles that approve timesheets must use two-factor authentication
+UPDATE "role" SET require_2fa=true WHERE id IN ('03ba326e-f9ed-410a-818f-eaa409c13622', '9dc8b32b-aefe-44d3-bf19-6dc088d13174');
+
+CREATE TABLE "user_two_factor" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "user_id" uuid NOT NULL,
+  "secret" text NOT NULL,
+  "confirmed_at" timestamp,
+  "last_used_step" bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_user_two_factor_id ON "user_two_factor" (id);
+CREATE INDEX idx_user_two_factor_created_at ON "user_two_factor" (created_at);
+CREATE INDEX idx_user_two_factor_updated_at ON "user_two_factor" (updated_at);
+CREATE UNIQUE INDEX idx_user_two_factor_user_id ON "user_two_factor" (user_id);
+
+CREATE TABLE "recovery_code" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "user_id" uuid NOT NULL,
+  "code_hash" text NOT NULL,
+  "used_at" timestamp
+);
+
+CREATE INDEX idx_recovery_code_id ON "recovery_code" (id);
+CREATE INDEX idx_recovery_code_created_at ON "recovery_code" (created_at);
+CREATE INDEX idx_recovery_code_updated_at ON "recovery_code" (updated_at);
+CREATE INDEX idx_recovery_code_user_id ON "recovery_code" (user_id);
+
+CREATE TABLE "mfa_challenge" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "user_id" uuid NOT NULL,
+  "token_hash" text NOT NULL,
+  "expired_at" timestamp NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_mfa_challenge_id ON "mfa_challenge" (id);
+CREATE INDEX idx_mfa_challenge_created_at ON "mfa_challenge" (created_at);
+CREATE INDEX idx_mfa_challenge_updated_at ON "mfa_challenge" (updated_at);
+CREATE INDEX idx_mfa_challenge_user_id ON "mfa_challenge" (user_id);
+CREATE UNIQUE INDEX idx_mfa_challenge_token_hash ON "mfa_challenge" (token_hash);
+CREATE INDEX idx_mfa_challenge_expired_at ON "mfa_challenge" (expired_at);
+
+ALTER TABLE "user_two_factor" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+ALTER TABLE "recovery_code" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+ALTER TABLE "mfa_challenge" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_password_reset_id, idx_password_reset_created_at, idx_password_reset_updated_at, idx_password_reset_user_id, idx_password_reset_token_hash, idx_password_reset_expired_at;
+
+DROP TABLE IF EXISTS public."password_reset";
@@ -0,0 +1,18 @@
+CREATE TABLE "password_reset" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "user_id" uuid NOT NULL,
+  "token_hash" text NOT NULL,
+  "expired_at" timestamp NOT NULL,
+  "used_at" timestamp
+);
+
+CREATE INDEX idx_password_reset_id ON "password_reset" (id);
+CREATE INDEX idx_password_reset_created_at ON "password_reset" (created_at);
+CREATE INDEX idx_password_reset_updated_at ON "password_reset" (updated_at);
+CREATE INDEX idx_password_reset_user_id ON "password_reset" (user_id);
+CREATE UNIQUE INDEX idx_password_reset_token_hash ON "password_reset" (token_hash);
+CREATE INDEX idx_password_reset_expired_at ON "password_reset" (expired_at);
+
+ALTER TABLE "password_reset" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_account_lockout_id, idx_account_lockout_created_at, idx_account_lockout_updated_at, idx_account_lockout_user_id;
+
+DROP TABLE IF EXISTS public."account_lockout";
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS "failed_login_count";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "locked_until";
@@ -0,0 +1,21 @@
+ALTER TABLE "user" ADD COLUMN "failed_login_count" int NOT NULL DEFAULT 0;
+ALTER TABLE "user" ADD COLUMN "locked_until" timestamp;
+
+CREATE TABLE "account_lockout" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "user_id" uuid NOT NULL,
+  "failed_login_count" int NOT NULL,
+  "locked_until" timestamp NOT NULL,
+  "unlocked_at" timestamp,
+  "unlocked_by" uuid
+);
+
+CREATE INDEX idx_account_lockout_id ON "account_lockout" (id);
+CREATE INDEX idx_account_lockout_created_at ON "account_lockout" (created_at);
+CREATE INDEX idx_account_lockout_updated_at ON "account_lockout" (updated_at);
+CREATE INDEX idx_account_lockout_user_id ON "account_lockout" (user_id);
+
+ALTER TABLE "account_lockout" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+ALTER TABLE "account_lockout" ADD FOREIGN KEY ("unlocked_by") REFERENCES "user" ("id") ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_api_key_id, idx_api_key_created_at, idx_api_key_updated_at, idx_api_key_user_id, idx_api_key_prefix;
+
+DROP TABLE IF EXISTS public."api_key";
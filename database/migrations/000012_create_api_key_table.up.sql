@@ -0,0 +1,20 @@
+CREATE TABLE "api_key" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "user_id" uuid NOT NULL,
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL,
+  "secret_hash" text NOT NULL,
+  "scopes" text[] NOT NULL DEFAULT '{}',
+  "expired_at" timestamp,
+  "last_used_at" timestamp
+);
+
+CREATE INDEX idx_api_key_id ON "api_key" (id);
+CREATE INDEX idx_api_key_created_at ON "api_key" (created_at);
+CREATE INDEX idx_api_key_updated_at ON "api_key" (updated_at);
+CREATE INDEX idx_api_key_user_id ON "api_key" (user_id);
+CREATE UNIQUE INDEX idx_api_key_prefix ON "api_key" (prefix);
+
+ALTER TABLE "api_key" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
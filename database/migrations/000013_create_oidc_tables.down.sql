@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_user_identity_id, idx_user_identity_created_at, idx_user_identity_updated_at, idx_user_identity_user_id, idx_user_identity_issuer_subject;
+
+DROP TABLE IF EXISTS public."user_identity";
+
+DROP INDEX IF EXISTS idx_oidc_login_id, idx_oidc_login_created_at, idx_oidc_login_updated_at, idx_oidc_login_state_hash, idx_oidc_login_expired_at;
+
+DROP TABLE IF EXISTS public."oidc_login";
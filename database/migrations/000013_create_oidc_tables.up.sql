@@ -0,0 +1,33 @@
+CREATE TABLE "oidc_login" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "state_hash" text NOT NULL,
+  "nonce" text NOT NULL,
+  "code_verifier" text NOT NULL,
+  "expired_at" timestamp NOT NULL
+);
+
+CREATE INDEX idx_oidc_login_id ON "oidc_login" (id);
+CREATE INDEX idx_oidc_login_created_at ON "oidc_login" (created_at);
+CREATE INDEX idx_oidc_login_updated_at ON "oidc_login" (updated_at);
+CREATE UNIQUE INDEX idx_oidc_login_state_hash ON "oidc_login" (state_hash);
+CREATE INDEX idx_oidc_login_expired_at ON "oidc_login" (expired_at);
+
+CREATE TABLE "user_identity" (
+  "id" UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now(),
+  "user_id" uuid NOT NULL,
+  "issuer" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "email" varchar
+);
+
+CREATE INDEX idx_user_identity_id ON "user_identity" (id);
+CREATE INDEX idx_user_identity_created_at ON "user_identity" (created_at);
+CREATE INDEX idx_user_identity_updated_at ON "user_identity" (updated_at);
+CREATE INDEX idx_user_identity_user_id ON "user_identity" (user_id);
+CREATE UNIQUE INDEX idx_user_identity_issuer_subject ON "user_identity" (issuer, subject);
+
+ALTER TABLE "user_identity" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE "session" DROP COLUMN IF EXISTS "ip_address";
+ALTER TABLE "session" DROP COLUMN IF EXISTS "user_agent";
+ALTER TABLE "session" DROP COLUMN IF EXISTS "device";
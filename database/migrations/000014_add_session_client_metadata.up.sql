@@ -0,0 +1,3 @@
+ALTER TABLE "session" ADD COLUMN "ip_address" varchar(64);
+ALTER TABLE "session" ADD COLUMN "user_agent" text;
+ALTER TABLE "session" ADD COLUMN "device" varchar(255);
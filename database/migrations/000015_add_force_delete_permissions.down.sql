@@ -0,0 +1 @@
+DELETE FROM "permission" WHERE name IN ('role:force-delete', 'project:force-delete', 'user:force-delete');
@@ -0,0 +1,10 @@
+-- roles, projects and users are soft deleted, removing them for good takes a separate permission
+INSERT INTO "permission" ("name","description") VALUES
+	 ('role:force-delete','Permanently delete roles'),
+	 ('project:force-delete','List deleted projects and permanently delete owned projects'),
+	 ('user:force-delete','Permanently delete users');
+
+-- Super Admin
+INSERT INTO "role_permission" ("role_id","permission_id")
+SELECT '03ba326e-f9ed-410a-818f-eaa409c13622', id FROM "permission"
+WHERE name IN ('role:force-delete', 'project:force-delete', 'user:force-delete');
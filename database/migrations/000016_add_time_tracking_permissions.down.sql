@@ -0,0 +1 @@
+DELETE FROM "permission" WHERE name IN ('time-entry:read', 'time-entry:write');
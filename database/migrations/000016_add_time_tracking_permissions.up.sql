@@ -0,0 +1,9 @@
+-- time tracking stays open to every signed-in user, the permissions let api keys be scoped to it
+INSERT INTO "permission" ("name","description") VALUES
+	 ('time-entry:read','List and view own time entries, timesheets and the running timer'),
+	 ('time-entry:write','Log time, run the timer and submit timesheets');
+
+-- every role
+INSERT INTO "role_permission" ("role_id","permission_id")
+SELECT r.id, p.id FROM "role" r, "permission" p
+WHERE p.name IN ('time-entry:read', 'time-entry:write');
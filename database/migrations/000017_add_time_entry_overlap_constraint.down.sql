@@ -0,0 +1 @@
+ALTER TABLE "time_entry" DROP CONSTRAINT IF EXISTS excl_time_entry_overlap;
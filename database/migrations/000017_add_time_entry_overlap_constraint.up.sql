@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- entries of one user never share an instant, a running entry is open-ended
+ALTER TABLE "time_entry" ADD CONSTRAINT excl_time_entry_overlap
+	EXCLUDE USING gist (user_id WITH =, tsrange(started_at, ended_at) WITH &&) WHERE (deleted_at IS NULL);
@@ -0,0 +1,3 @@
+DELETE FROM "permission" WHERE name='project:read';
+
+UPDATE "permission" SET description='Create projects' WHERE name='project:write';
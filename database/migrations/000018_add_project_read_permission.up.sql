@@ -0,0 +1,11 @@
+-- reading projects stays open to every signed-in user, the permission lets api keys be scoped to it
+INSERT INTO "permission" ("name","description") VALUES
+	 ('project:read','List and view own projects and their members');
+
+UPDATE "permission" SET description='Create projects, api keys also need it to change projects and their members'
+WHERE name='project:write';
+
+-- every role
+INSERT INTO "role_permission" ("role_id","permission_id")
+SELECT r.id, p.id FROM "role" r, "permission" p
+WHERE p.name='project:read';
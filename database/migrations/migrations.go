@@ -0,0 +1,398 @@
+// Package migrations embeds the schema migrations of the database in the
+// binary and applies them. Applied migrations are recorded in
+// schema_migrations with the checksum of their up script, so a migration
+// edited after it ran is reported instead of silently diverging.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// ErrChecksumMismatch is returned when applied migrations were changed since.
+var ErrChecksumMismatch = errors.New("applied migrations were modified")
+
+const (
+	queryCreateTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY NOT NULL,
+		name varchar NOT NULL,
+		checksum varchar NOT NULL,
+		applied_at timestamp NOT NULL DEFAULT now()
+	)`
+	queryColumns       = `SELECT column_name FROM information_schema.columns WHERE table_schema=current_schema() AND table_name='schema_migrations'`
+	queryLegacyVersion = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	queryDropTable     = `DROP TABLE schema_migrations`
+	queryApplied       = `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version ASC`
+	queryIsApplied     = `SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version=$1)`
+	queryInsert        = `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`
+	queryDelete        = `DELETE FROM schema_migrations WHERE version=$1`
+	queryLock          = `SELECT pg_advisory_xact_lock($1)`
+)
+
+var (
+	// fileName matches 000001_create_initial_table.up.sql
+	fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	nonWord  = regexp.MustCompile(`\W+`)
+)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status is a migration as seen from the database. Pending migrations have
+// no AppliedAt, Modified ones were changed after they were applied and
+// Missing ones were applied but are not known to this binary.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+	Modified  bool
+	Missing   bool
+}
+
+type applied struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// New reads the migrations embedded in the binary.
+func New(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("error reading migrations: version %d is used by %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(script)
+			sum := sha256.Sum256(script)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Checksum == "" {
+			return nil, fmt.Errorf("error reading migrations: version %d has no up script", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return int(a.Version - b.Version)
+	})
+
+	return migrations, nil
+}
+
+// Up applies the pending migrations in order, each in its own transaction,
+// and returns those it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var modified []string
+	for _, s := range statuses {
+		if s.Modified {
+			modified = append(modified, strconv.FormatInt(s.Version, 10))
+		}
+	}
+	if len(modified) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(modified, ", "))
+	}
+
+	var done []Migration
+	for _, s := range statuses {
+		if s.AppliedAt != nil || s.Missing {
+			continue
+		}
+
+		ran, err := m.apply(ctx, s.Migration)
+		if err != nil {
+			return done, err
+		}
+		if ran {
+			done = append(done, s.Migration)
+		}
+	}
+
+	return done, nil
+}
+
+// Down rolls back the last steps applied migrations, latest first, and
+// returns those it rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(statuses) - 1; i >= 0 && len(done) < steps; i-- {
+		s := statuses[i]
+		if s.AppliedAt == nil {
+			continue
+		}
+		if s.Missing {
+			return done, fmt.Errorf("error rolling back migration %d: not known to this binary", s.Version)
+		}
+
+		if err := m.rollback(ctx, s.Migration); err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+
+	return done, nil
+}
+
+// Status lists the known and the applied migrations by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.prepare(ctx); err != nil {
+		return nil, err
+	}
+
+	var rows []applied
+	if err := m.db.SelectContext(ctx, &rows, queryApplied); err != nil {
+		return nil, fmt.Errorf("error listing applied migrations: %w", err)
+	}
+
+	appliedAt := map[int64]applied{}
+	for _, row := range rows {
+		appliedAt[row.Version] = row
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		s := Status{Migration: migration}
+		if row, ok := appliedAt[migration.Version]; ok {
+			s.AppliedAt = &row.AppliedAt
+			s.Modified = row.Checksum != migration.Checksum
+			delete(appliedAt, migration.Version)
+		}
+		statuses = append(statuses, s)
+	}
+
+	for _, row := range appliedAt {
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: row.Version, Name: row.Name, Checksum: row.Checksum},
+			AppliedAt: &row.AppliedAt,
+			Missing:   true,
+		})
+	}
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return int(a.Version - b.Version)
+	})
+
+	return statuses, nil
+}
+
+// prepare creates schema_migrations, taking over the version recorded by
+// golang-migrate when the database was migrated with its CLI before.
+func (m *Migrator) prepare(ctx context.Context) error {
+	return m.inLock(ctx, func(tx *sqlx.Tx) error {
+		var columns []string
+		if err := tx.SelectContext(ctx, &columns, queryColumns); err != nil {
+			return fmt.Errorf("error preparing schema_migrations: %w", err)
+		}
+
+		if !slices.Contains(columns, "dirty") {
+			if _, err := tx.ExecContext(ctx, queryCreateTable); err != nil {
+				return fmt.Errorf("error preparing schema_migrations: %w", err)
+			}
+			return nil
+		}
+
+		var version int64
+		var dirty bool
+		err := tx.QueryRowxContext(ctx, queryLegacyVersion).Scan(&version, &dirty)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("error reading golang-migrate version: %w", err)
+		}
+		if dirty {
+			return fmt.Errorf("error reading golang-migrate version: migration %d failed halfway and must be fixed by hand", version)
+		}
+
+		if _, err := tx.ExecContext(ctx, queryDropTable); err != nil {
+			return fmt.Errorf("error preparing schema_migrations: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, queryCreateTable); err != nil {
+			return fmt.Errorf("error preparing schema_migrations: %w", err)
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, err := tx.ExecContext(ctx, queryInsert, migration.Version, migration.Name, migration.Checksum); err != nil {
+				return fmt.Errorf("error recording migration %d: %w", migration.Version, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// apply runs the up script of a migration unless another replica applied it
+// in the meantime, which is reported by returning false.
+func (m *Migrator) apply(ctx context.Context, migration Migration) (bool, error) {
+	ran := false
+	err := m.inLock(ctx, func(tx *sqlx.Tx) error {
+		var exists bool
+		if err := tx.QueryRowxContext(ctx, queryIsApplied, migration.Version).Scan(&exists); err != nil {
+			return fmt.Errorf("error applying migration %d: %w", migration.Version, err)
+		}
+		if exists {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return fmt.Errorf("error applying migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, queryInsert, migration.Version, migration.Name, migration.Checksum); err != nil {
+			return fmt.Errorf("error recording migration %d: %w", migration.Version, err)
+		}
+
+		ran = true
+		return nil
+	})
+
+	return ran, err
+}
+
+func (m *Migrator) rollback(ctx context.Context, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("error rolling back migration %d_%s: no down script", migration.Version, migration.Name)
+	}
+
+	return m.inLock(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return fmt.Errorf("error rolling back migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, queryDelete, migration.Version); err != nil {
+			return fmt.Errorf("error recording rollback of migration %d: %w", migration.Version, err)
+		}
+
+		return nil
+	})
+}
+
+// inLock runs fn in a transaction holding the migration lock, which is
+// released with the transaction.
+func (m *Migrator) inLock(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error acquiring migration lock: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, queryLock, lockKey()); err != nil {
+		return fmt.Errorf("error acquiring migration lock: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockKey serializes migrations run by several replicas at once.
+func lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte("schema_migrations"))
+	return int64(h.Sum64())
+}
+
+// Create writes empty up and down scripts for a new migration to dir, which
+// is the migrations directory of the source tree, and returns their paths.
+func Create(dir, name string) (string, string, error) {
+	name = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("error creating migration: name is empty")
+	}
+
+	existing, err := load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+
+	var version int64 = 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	up := filepath.Join(dir, fmt.Sprintf("%06d_%s.up.sql", version, name))
+	down := filepath.Join(dir, fmt.Sprintf("%06d_%s.down.sql", version, name))
+	for _, path := range []string{up, down} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			return "", "", fmt.Errorf("error creating migration: %w", err)
+		}
+	}
+
+	return up, down, nil
+}
@@ -0,0 +1,265 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func withTestDB(t *testing.T, fn func(*sqlx.DB, sqlmock.Sqlmock)) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+
+	fn(db, mock)
+}
+
+func checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+var testFS = fstest.MapFS{
+	"000010_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON t (c)")},
+	"000010_add_index.down.sql":    {Data: []byte("DROP INDEX idx")},
+	"000002_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c int)")},
+	"000002_create_table.down.sql": {Data: []byte("DROP TABLE t")},
+	"000001_init.up.sql":           {Data: []byte("SELECT 1")},
+	"README.md":                    {Data: []byte("not a migration")},
+}
+
+func testMigrator(t *testing.T, db *sqlx.DB) *Migrator {
+	migrations, err := load(testFS)
+	require.NoError(t, err)
+
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// expectPrepare expects schema_migrations to exist already.
+func expectPrepare(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec(queryLock).WithArgs(lockKey()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(queryColumns).WillReturnRows(sqlmock.NewRows([]string{"column_name"}).
+		AddRow("version").AddRow("name").AddRow("checksum").AddRow("applied_at"))
+	mock.ExpectExec(queryCreateTable).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func appliedRows(migrations ...Migration) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"})
+	for _, m := range migrations {
+		rows.AddRow(m.Version, m.Name, m.Checksum, time.Now())
+	}
+	return rows
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should order migrations by version and checksum their up script", func(t *testing.T) {
+		migrations, err := load(testFS)
+		require.NoError(t, err)
+		require.Len(t, migrations, 3)
+
+		require.Equal(t, int64(1), migrations[0].Version)
+		require.Equal(t, int64(2), migrations[1].Version)
+		require.Equal(t, int64(10), migrations[2].Version)
+
+		require.Equal(t, "create_table", migrations[1].Name)
+		require.Equal(t, "CREATE TABLE t (c int)", migrations[1].Up)
+		require.Equal(t, "DROP TABLE t", migrations[1].Down)
+		require.Equal(t, checksum("CREATE TABLE t (c int)"), migrations[1].Checksum)
+	})
+
+	t.Run("should reject a version without up script", func(t *testing.T) {
+		_, err := load(fstest.MapFS{"000001_init.down.sql": {Data: []byte("SELECT 1")}})
+		require.ErrorContains(t, err, "has no up script")
+	})
+
+	t.Run("should reject a version used twice", func(t *testing.T) {
+		_, err := load(fstest.MapFS{
+			"000001_init.up.sql":  {Data: []byte("SELECT 1")},
+			"000001_other.up.sql": {Data: []byte("SELECT 2")},
+		})
+		require.ErrorContains(t, err, "version 1 is used by")
+	})
+
+	t.Run("should load the embedded migrations", func(t *testing.T) {
+		migrations, err := load(files)
+		require.NoError(t, err)
+		require.NotEmpty(t, migrations)
+		require.Equal(t, "create_initial_table", migrations[0].Name)
+	})
+}
+
+func TestUp(t *testing.T) {
+	tcs := []struct {
+		name string
+		test func(t *testing.T, m *Migrator, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "should apply pending migrations in version order",
+			test: func(t *testing.T, m *Migrator, mock sqlmock.Sqlmock) {
+				expectPrepare(mock)
+				mock.ExpectQuery(queryApplied).WillReturnRows(appliedRows(m.migrations[0]))
+
+				for _, migration := range m.migrations[1:] {
+					mock.ExpectBegin()
+					mock.ExpectExec(queryLock).WithArgs(lockKey()).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(queryIsApplied).WithArgs(migration.Version).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+					mock.ExpectExec(migration.Up).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(queryInsert).WithArgs(migration.Version, migration.Name, migration.Checksum).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				}
+
+				done, err := m.Up(context.Background())
+				require.NoError(t, err)
+				require.Len(t, done, 2)
+				require.Equal(t, int64(2), done[0].Version)
+				require.Equal(t, int64(10), done[1].Version)
+			},
+		},
+		{
+			name: "should skip a migration another replica applied meanwhile",
+			test: func(t *testing.T, m *Migrator, mock sqlmock.Sqlmock) {
+				expectPrepare(mock)
+				mock.ExpectQuery(queryApplied).WillReturnRows(appliedRows(m.migrations[0], m.migrations[1]))
+
+				mock.ExpectBegin()
+				mock.ExpectExec(queryLock).WithArgs(lockKey()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(queryIsApplied).WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectCommit()
+
+				done, err := m.Up(context.Background())
+				require.NoError(t, err)
+				require.Empty(t, done)
+			},
+		},
+		{
+			name: "should refuse to run when an applied migration was modified",
+			test: func(t *testing.T, m *Migrator, mock sqlmock.Sqlmock) {
+				modified := m.migrations[1]
+				modified.Checksum = checksum("CREATE TABLE t (c bigint)")
+
+				expectPrepare(mock)
+				mock.ExpectQuery(queryApplied).WillReturnRows(appliedRows(m.migrations[0], modified))
+
+				done, err := m.Up(context.Background())
+				require.ErrorIs(t, err, ErrChecksumMismatch)
+				require.ErrorContains(t, err, ": 2")
+				require.Empty(t, done)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				tc.test(t, testMigrator(t, db), mock)
+				require.NoError(t, mock.ExpectationsWereMet())
+			})
+		})
+	}
+}
+
+func TestDown(t *testing.T) {
+	tcs := []struct {
+		name string
+		test func(t *testing.T, m *Migrator, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "should roll back the latest migrations first",
+			test: func(t *testing.T, m *Migrator, mock sqlmock.Sqlmock) {
+				expectPrepare(mock)
+				mock.ExpectQuery(queryApplied).WillReturnRows(appliedRows(m.migrations...))
+
+				for _, migration := range []Migration{m.migrations[2], m.migrations[1]} {
+					mock.ExpectBegin()
+					mock.ExpectExec(queryLock).WithArgs(lockKey()).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(migration.Down).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(queryDelete).WithArgs(migration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				}
+
+				done, err := m.Down(context.Background(), 2)
+				require.NoError(t, err)
+				require.Len(t, done, 2)
+				require.Equal(t, int64(10), done[0].Version)
+				require.Equal(t, int64(2), done[1].Version)
+			},
+		},
+		{
+			name: "should fail on a migration without down script",
+			test: func(t *testing.T, m *Migrator, mock sqlmock.Sqlmock) {
+				expectPrepare(mock)
+				mock.ExpectQuery(queryApplied).WillReturnRows(appliedRows(m.migrations[0]))
+
+				done, err := m.Down(context.Background(), 1)
+				require.ErrorContains(t, err, "no down script")
+				require.Empty(t, done)
+			},
+		},
+		{
+			name: "should fail on a migration unknown to the binary",
+			test: func(t *testing.T, m *Migrator, mock sqlmock.Sqlmock) {
+				expectPrepare(mock)
+				mock.ExpectQuery(queryApplied).WillReturnRows(appliedRows(append(m.migrations, Migration{Version: 11, Name: "newer", Checksum: "x"})...))
+
+				_, err := m.Down(context.Background(), 1)
+				require.ErrorContains(t, err, "not known to this binary")
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				tc.test(t, testMigrator(t, db), mock)
+				require.NoError(t, mock.ExpectationsWereMet())
+			})
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("should number the migration after the latest one", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "000001_init.up.sql"), []byte("SELECT 1"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "000009_add_index.up.sql"), []byte("SELECT 1"), 0o644))
+
+		up, down, err := Create(dir, "Add user-email Index!")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "000010_add_user_email_index.up.sql"), up)
+		require.Equal(t, filepath.Join(dir, "000010_add_user_email_index.down.sql"), down)
+
+		require.FileExists(t, up)
+		require.FileExists(t, down)
+	})
+
+	t.Run("should start at 1 in an empty directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		up, _, err := Create(dir, "init")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "000001_init.up.sql"), up)
+	})
+
+	t.Run("should reject an empty name", func(t *testing.T) {
+		_, _, err := Create(t.TempDir(), " !! ")
+		require.ErrorContains(t, err, "name is empty")
+	})
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AccountLockoutRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccountLockoutRepository(db *sqlx.DB) *AccountLockoutRepository {
+	return &AccountLockoutRepository{
+		db: db,
+	}
+}
+
+// RecordFailedSignIn counts a failed sign-in of the user and returns the
+// number of consecutive failures so far.
+func (repo *AccountLockoutRepository) RecordFailedSignIn(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+
+	const query_increment = `
+		UPDATE "user" SET failed_login_count=failed_login_count+1
+		WHERE id=$1
+		RETURNING failed_login_count
+	`
+
+	err := repo.db.GetContext(ctx, &count, query_increment, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error recording failed sign-in: %w", err)
+	}
+
+	return count, nil
+}
+
+// ResetFailedSignIns clears the failure counter and any lock of the user
+// after a successful sign-in.
+func (repo *AccountLockoutRepository) ResetFailedSignIns(ctx context.Context, userID uuid.UUID) error {
+	const query_reset = `
+		UPDATE "user" SET failed_login_count=0, locked_until=NULL
+		WHERE id=$1
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_reset, userID)
+	if err != nil {
+		return fmt.Errorf("error resetting failed sign-ins: %v", err)
+	}
+
+	return nil
+}
+
+// LockUser locks the user until r.LockedUntil and records the lockout in a
+// single transaction.
+func (repo *AccountLockoutRepository) LockUser(ctx context.Context, r *entity.AccountLockout) (*entity.AccountLockout, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error locking user: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_lock = `
+		UPDATE "user" SET locked_until=$2, updated_at=now()
+		WHERE id=$1
+	`
+
+	if _, err := tx.ExecContext(ctx, query_lock, r.UserID, r.LockedUntil); err != nil {
+		return nil, fmt.Errorf("error locking user: %w", err)
+	}
+
+	const query_insert = `
+		INSERT INTO "account_lockout" (user_id, failed_login_count, locked_until)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query_insert, r.UserID, r.FailedLoginCount, r.LockedUntil).
+		Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting account lockout: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error locking user: %w", err)
+	}
+
+	return r, nil
+}
+
+// UnlockUser lifts the lock of the user, clears the failure counter and
+// records who unlocked the account on the open lockouts.
+func (repo *AccountLockoutRepository) UnlockUser(ctx context.Context, userID uuid.UUID, unlockedBy uuid.UUID) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error unlocking user: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_unlock = `
+		UPDATE "user" SET failed_login_count=0, locked_until=NULL, updated_at=now()
+		WHERE id=$1
+	`
+
+	if _, err := tx.ExecContext(ctx, query_unlock, userID); err != nil {
+		return fmt.Errorf("error unlocking user: %w", err)
+	}
+
+	const query_close = `
+		UPDATE "account_lockout" SET unlocked_at=now(), unlocked_by=$2, updated_at=now()
+		WHERE user_id=$1 AND unlocked_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, query_close, userID, unlockedBy); err != nil {
+		return fmt.Errorf("error updating account lockout: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error unlocking user: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *AccountLockoutRepository) ListAccountLockouts(ctx context.Context, userID uuid.UUID) ([]entity.AccountLockout, error) {
+	var lockouts []entity.AccountLockout
+
+	const query_find_by_user = `
+		SELECT * FROM "account_lockout"
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+	`
+
+	err := repo.db.SelectContext(ctx, &lockouts, query_find_by_user, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing account lockouts: %v", err)
+	}
+
+	return lockouts, nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordFailedSignIn(t *testing.T) {
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *AccountLockoutRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *AccountLockoutRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE "user" SET failed_login_count=failed_login_count+1 WHERE id=$1 RETURNING failed_login_count`).
+					WithArgs(userID).
+					WillReturnRows(sqlmock.NewRows([]string{"failed_login_count"}).AddRow(3))
+
+				count, err := repo.RecordFailedSignIn(context.Background(), userID)
+				require.NoError(t, err)
+				require.Equal(t, 3, count)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed recording sign-in",
+			test: func(t *testing.T, repo *AccountLockoutRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE "user" SET failed_login_count=failed_login_count+1 WHERE id=$1 RETURNING failed_login_count`).
+					WithArgs(userID).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.RecordFailedSignIn(context.Background(), userID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewAccountLockoutRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestLockUser(t *testing.T) {
+	lockout := &entity.AccountLockout{
+		UserID:           uuid.New(),
+		FailedLoginCount: 5,
+		LockedUntil:      time.Now().Add(time.Minute),
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *AccountLockoutRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *AccountLockoutRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user" SET locked_until=$2, updated_at=now() WHERE id=$1`).
+					WithArgs(lockout.UserID, lockout.LockedUntil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "account_lockout" (user_id, failed_login_count, locked_until) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
+					WithArgs(lockout.UserID, lockout.FailedLoginCount, lockout.LockedUntil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, time.Now(), time.Now()))
+				mock.ExpectCommit()
+
+				record, err := repo.LockUser(context.Background(), lockout)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting account lockout",
+			test: func(t *testing.T, repo *AccountLockoutRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user" SET locked_until=$2, updated_at=now() WHERE id=$1`).
+					WithArgs(lockout.UserID, lockout.LockedUntil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "account_lockout" (user_id, failed_login_count, locked_until) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
+					WithArgs(lockout.UserID, lockout.FailedLoginCount, lockout.LockedUntil).
+					WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+
+				_, err := repo.LockUser(context.Background(), lockout)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewAccountLockoutRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestUnlockUser(t *testing.T) {
+	userID := uuid.New()
+	adminID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *AccountLockoutRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *AccountLockoutRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user" SET failed_login_count=0, locked_until=NULL, updated_at=now() WHERE id=$1`).
+					WithArgs(userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "account_lockout" SET unlocked_at=now(), unlocked_by=$2, updated_at=now() WHERE user_id=$1 AND unlocked_at IS NULL`).
+					WithArgs(userID, adminID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				err := repo.UnlockUser(context.Background(), userID, adminID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed unlocking user",
+			test: func(t *testing.T, repo *AccountLockoutRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user" SET failed_login_count=0, locked_until=NULL, updated_at=now() WHERE id=$1`).
+					WithArgs(userID).
+					WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+
+				err := repo.UnlockUser(context.Background(), userID, adminID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewAccountLockoutRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+func (repo *APIKeyRepository) CreateAPIKey(ctx context.Context, r *entity.APIKey) (*entity.APIKey, error) {
+	const query_insert = `
+		INSERT INTO "api_key" (user_id, name, prefix, secret_hash, scopes, expired_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := repo.db.QueryRowContext(ctx, query_insert, r.UserID, r.Name, r.Prefix, r.SecretHash, r.Scopes, r.ExpiredAt).
+		Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting api key: %w", err)
+	}
+
+	return r, nil
+}
+
+// GetAPIKey returns the key only when it belongs to the user.
+func (repo *APIKeyRepository) GetAPIKey(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entity.APIKey, error) {
+	var r entity.APIKey
+
+	const query_find_one = `
+		SELECT * FROM "api_key"
+		WHERE id=$1 AND user_id=$2
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_one, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting api key: %w", notFound("api key", err))
+	}
+
+	return &r, nil
+}
+
+func (repo *APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	var r entity.APIKey
+
+	const query_find_by_prefix = `
+		SELECT * FROM "api_key"
+		WHERE prefix=$1
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_by_prefix, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("error getting api key: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *APIKeyRepository) ListAPIKeysByUser(ctx context.Context, userID uuid.UUID) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+
+	const query_find_by_user = `
+		SELECT * FROM "api_key"
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+	`
+
+	err := repo.db.SelectContext(ctx, &keys, query_find_by_user, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing api keys: %v", err)
+	}
+
+	return keys, nil
+}
+
+func (repo *APIKeyRepository) UpdateAPIKey(ctx context.Context, r *entity.APIKey) (*entity.APIKey, error) {
+	const query_update = `
+		UPDATE "api_key" SET name=:name, scopes=:scopes, expired_at=:expired_at, updated_at=:updated_at
+		WHERE id=:id AND user_id=:user_id
+	`
+
+	_, err := repo.db.NamedExecContext(ctx, query_update, r)
+	if err != nil {
+		return nil, fmt.Errorf("error updating api key: %w", err)
+	}
+
+	return r, nil
+}
+
+// TouchAPIKey records that the key was just used. The timestamp is only
+// written once a minute to keep busy keys from rewriting the row on every
+// request.
+func (repo *APIKeyRepository) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	const query_touch = `
+		UPDATE "api_key" SET last_used_at=now()
+		WHERE id=$1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_touch, id)
+	if err != nil {
+		return fmt.Errorf("error updating api key: %v", err)
+	}
+
+	return nil
+}
+
+func (repo *APIKeyRepository) DeleteAPIKey(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	const query_delete = `
+		DELETE FROM "api_key"
+		WHERE id=$1 AND user_id=$2
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_delete, id, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting api key: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	key := &entity.APIKey{
+		UserID:     uuid.New(),
+		Name:       "ci",
+		Prefix:     "a1b2c3d4",
+		SecretHash: "hash",
+		Scopes:     pq.StringArray{"project:write"},
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *APIKeyRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *APIKeyRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "api_key" (user_id, name, prefix, secret_hash, scopes, expired_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`).
+					WithArgs(key.UserID, key.Name, key.Prefix, key.SecretHash, key.Scopes, key.ExpiredAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, time.Now(), time.Now()))
+
+				record, err := repo.CreateAPIKey(context.Background(), key)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting api key",
+			test: func(t *testing.T, repo *APIKeyRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "api_key" (user_id, name, prefix, secret_hash, scopes, expired_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`).
+					WithArgs(key.UserID, key.Name, key.Prefix, key.SecretHash, key.Scopes, key.ExpiredAt).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.CreateAPIKey(context.Background(), key)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewAPIKeyRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestGetAPIKeyByPrefix(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *APIKeyRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *APIKeyRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "name", "prefix", "secret_hash", "scopes", "expired_at", "last_used_at"}).
+					AddRow(expectedID, time.Now(), time.Now(), uuid.New(), "ci", "a1b2c3d4", "hash", "{project:write,role:read}", nil, nil)
+
+				mock.ExpectQuery(`SELECT * FROM "api_key" WHERE prefix=$1`).
+					WithArgs("a1b2c3d4").
+					WillReturnRows(rows)
+
+				record, err := repo.GetAPIKeyByPrefix(context.Background(), "a1b2c3d4")
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, pq.StringArray{"project:write", "role:read"}, record.Scopes)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "api key not found",
+			test: func(t *testing.T, repo *APIKeyRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "api_key" WHERE prefix=$1`).
+					WithArgs("a1b2c3d4").
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetAPIKeyByPrefix(context.Background(), "a1b2c3d4")
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewAPIKeyRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	id := uuid.New()
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *APIKeyRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *APIKeyRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "api_key" WHERE id=$1 AND user_id=$2`).
+					WithArgs(id, userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				err := repo.DeleteAPIKey(context.Background(), id, userID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed deleting api key",
+			test: func(t *testing.T, repo *APIKeyRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "api_key" WHERE id=$1 AND user_id=$2`).
+					WithArgs(id, userID).
+					WillReturnError(fmt.Errorf("some error"))
+
+				err := repo.DeleteAPIKey(context.Background(), id, userID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewAPIKeyRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"gofi/pkg/apperror"
+)
+
+// notFound turns a lookup that matched no row into a not found domain error
+// naming the entity, sql.ErrNoRows stays in the chain.
+func notFound(name string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.Wrap(apperror.ErrNotFound, name+" not found", err)
+	}
+
+	return err
+}
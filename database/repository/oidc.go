@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OIDCRepository struct {
+	db *sqlx.DB
+}
+
+func NewOIDCRepository(db *sqlx.DB) *OIDCRepository {
+	return &OIDCRepository{
+		db: db,
+	}
+}
+
+func (repo *OIDCRepository) CreateOIDCLogin(ctx context.Context, r *entity.OIDCLogin) (*entity.OIDCLogin, error) {
+	const query_insert = `
+		INSERT INTO "oidc_login" (state_hash, nonce, code_verifier, expired_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := repo.db.QueryRowContext(ctx, query_insert, r.StateHash, r.Nonce, r.CodeVerifier, r.ExpiredAt).
+		Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting oidc login: %w", err)
+	}
+
+	return r, nil
+}
+
+// TakeOIDCLogin removes and returns the pending login for the state, so each
+// state can only complete one callback.
+func (repo *OIDCRepository) TakeOIDCLogin(ctx context.Context, stateHash string) (*entity.OIDCLogin, error) {
+	var r entity.OIDCLogin
+
+	const query_take = `
+		DELETE FROM "oidc_login"
+		WHERE state_hash=$1
+		RETURNING *
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_take, stateHash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting oidc login: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *OIDCRepository) GetUserIdentity(ctx context.Context, issuer string, subject string) (*entity.UserIdentity, error) {
+	var r entity.UserIdentity
+
+	const query_find_one = `
+		SELECT * FROM "user_identity"
+		WHERE issuer=$1 AND subject=$2
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_one, issuer, subject)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user identity: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *OIDCRepository) CreateUserIdentity(ctx context.Context, r *entity.UserIdentity) (*entity.UserIdentity, error) {
+	const query_insert = `
+		INSERT INTO "user_identity" (user_id, issuer, subject, email)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := repo.db.QueryRowContext(ctx, query_insert, r.UserID, r.Issuer, r.Subject, r.Email).
+		Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting user identity: %w", err)
+	}
+
+	return r, nil
+}
+
+// ProvisionUser creates the user together with its provider identity in a
+// single transaction.
+func (repo *OIDCRepository) ProvisionUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) (*entity.User, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error provisioning user: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_insert_user = `
+		INSERT INTO "user" (fullname, email, password, phone, token_verify, is_active, is_blocked, role_id, upload_id, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query_insert_user, user.Fullname, user.Email, user.Password, user.Phone, user.TokenVerify, user.IsActive, user.IsBlocked, user.RoleID, user.UploadID, user.Timezone).
+		Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting user: %w", err)
+	}
+
+	identity.UserID = user.ID
+
+	const query_insert_identity = `
+		INSERT INTO "user_identity" (user_id, issuer, subject, email)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query_insert_identity, identity.UserID, identity.Issuer, identity.Subject, identity.Email).
+		Scan(&identity.ID, &identity.CreatedAt, &identity.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting user identity: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error provisioning user: %w", err)
+	}
+
+	return user, nil
+}
+
+// PurgeExpiredOIDCLogins deletes the oidc logins that expired before the given time.
+func (repo *OIDCRepository) PurgeExpiredOIDCLogins(ctx context.Context, before time.Time) (int64, error) {
+	const query_purge = `
+		DELETE FROM "oidc_login"
+		WHERE expired_at < $1
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_purge, before)
+	if err != nil {
+		return 0, fmt.Errorf("error purging oidc logins: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error purging oidc logins: %w", err)
+	}
+
+	return affected, nil
+}
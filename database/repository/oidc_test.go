@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeOIDCLogin(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *OIDCRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *OIDCRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "state_hash", "nonce", "code_verifier", "expired_at"}).
+					AddRow(expectedID, time.Now(), time.Now(), "hash", "nonce", "verifier", time.Now().Add(10*time.Minute))
+
+				mock.ExpectQuery(`DELETE FROM "oidc_login" WHERE state_hash=$1 RETURNING *`).
+					WithArgs("hash").
+					WillReturnRows(rows)
+
+				record, err := repo.TakeOIDCLogin(context.Background(), "hash")
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, "verifier", record.CodeVerifier)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "oidc login not found",
+			test: func(t *testing.T, repo *OIDCRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`DELETE FROM "oidc_login" WHERE state_hash=$1 RETURNING *`).
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.TakeOIDCLogin(context.Background(), "hash")
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewOIDCRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestGetUserIdentity(t *testing.T) {
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *OIDCRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *OIDCRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "issuer", "subject", "email"}).
+					AddRow(uuid.New(), time.Now(), time.Now(), userID, "https://idp.example.com", "248289761001", "jane@example.com")
+
+				mock.ExpectQuery(`SELECT * FROM "user_identity" WHERE issuer=$1 AND subject=$2`).
+					WithArgs("https://idp.example.com", "248289761001").
+					WillReturnRows(rows)
+
+				record, err := repo.GetUserIdentity(context.Background(), "https://idp.example.com", "248289761001")
+				require.NoError(t, err)
+				require.Equal(t, userID, record.UserID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "user identity not found",
+			test: func(t *testing.T, repo *OIDCRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "user_identity" WHERE issuer=$1 AND subject=$2`).
+					WithArgs("https://idp.example.com", "248289761001").
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetUserIdentity(context.Background(), "https://idp.example.com", "248289761001")
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewOIDCRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestProvisionUser(t *testing.T) {
+	email := "jane@example.com"
+	expectedUserID := uuid.New()
+
+	newUser := func() *entity.User {
+		return &entity.User{
+			Fullname: "Jane Doe",
+			Email:    email,
+			Password: "hash",
+			IsActive: true,
+			RoleID:   uuid.New(),
+			Timezone: "UTC",
+		}
+	}
+
+	newIdentity := func() *entity.UserIdentity {
+		return &entity.UserIdentity{
+			Issuer:  "https://idp.example.com",
+			Subject: "248289761001",
+			Email:   &email,
+		}
+	}
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *OIDCRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *OIDCRepository, mock sqlmock.Sqlmock) {
+				user := newUser()
+				identity := newIdentity()
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO "user" (fullname, email, password, phone, token_verify, is_active, is_blocked, role_id, upload_id, timezone) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at`).
+					WithArgs(user.Fullname, user.Email, user.Password, user.Phone, user.TokenVerify, user.IsActive, user.IsBlocked, user.RoleID, user.UploadID, user.Timezone).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedUserID, time.Now(), time.Now()))
+				mock.ExpectQuery(`INSERT INTO "user_identity" (user_id, issuer, subject, email) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`).
+					WithArgs(expectedUserID, identity.Issuer, identity.Subject, identity.Email).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(uuid.New(), time.Now(), time.Now()))
+				mock.ExpectCommit()
+
+				record, err := repo.ProvisionUser(context.Background(), user, identity)
+				require.NoError(t, err)
+				require.Equal(t, expectedUserID, record.ID)
+				require.Equal(t, expectedUserID, identity.UserID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting user identity",
+			test: func(t *testing.T, repo *OIDCRepository, mock sqlmock.Sqlmock) {
+				user := newUser()
+				identity := newIdentity()
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO "user" (fullname, email, password, phone, token_verify, is_active, is_blocked, role_id, upload_id, timezone) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at`).
+					WithArgs(user.Fullname, user.Email, user.Password, user.Phone, user.TokenVerify, user.IsActive, user.IsBlocked, user.RoleID, user.UploadID, user.Timezone).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedUserID, time.Now(), time.Now()))
+				mock.ExpectQuery(`INSERT INTO "user_identity" (user_id, issuer, subject, email) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`).
+					WithArgs(expectedUserID, identity.Issuer, identity.Subject, identity.Email).
+					WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+
+				_, err := repo.ProvisionUser(context.Background(), user, identity)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewOIDCRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestPurgeExpiredOIDCLogins(t *testing.T) {
+	before := time.Now().UTC()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *OIDCRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *OIDCRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "oidc_login" WHERE expired_at < $1`).
+					WithArgs(before).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				purged, err := repo.PurgeExpiredOIDCLogins(context.Background(), before)
+				require.NoError(t, err)
+				require.Equal(t, int64(2), purged)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed purging oidc logins",
+			test: func(t *testing.T, repo *OIDCRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "oidc_login" WHERE expired_at < $1`).
+					WithArgs(before).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.PurgeExpiredOIDCLogins(context.Background(), before)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewOIDCRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PasswordResetRepository struct {
+	db *sqlx.DB
+}
+
+func NewPasswordResetRepository(db *sqlx.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: db,
+	}
+}
+
+// CreatePasswordReset stores a new reset token for the user. Older unused
+// tokens of the user are invalidated so only the latest link works.
+func (repo *PasswordResetRepository) CreatePasswordReset(ctx context.Context, r *entity.PasswordReset) (*entity.PasswordReset, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting password reset: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_invalidate = `
+		UPDATE "password_reset" SET used_at=now(), updated_at=now()
+		WHERE user_id=$1 AND used_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, query_invalidate, r.UserID); err != nil {
+		return nil, fmt.Errorf("error invalidating password resets: %w", err)
+	}
+
+	const query_insert = `
+		INSERT INTO "password_reset" (user_id, token_hash, expired_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query_insert, r.UserID, r.TokenHash, r.ExpiredAt).
+		Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting password reset: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error inserting password reset: %w", err)
+	}
+
+	return r, nil
+}
+
+func (repo *PasswordResetRepository) GetPasswordResetByTokenHash(ctx context.Context, hash string) (*entity.PasswordReset, error) {
+	var r entity.PasswordReset
+
+	const query_find_by_token = `
+		SELECT * FROM "password_reset"
+		WHERE token_hash=$1
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_by_token, hash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting password reset: %w", err)
+	}
+
+	return &r, nil
+}
+
+// ResetPassword consumes the reset token, stores the new password hash, lifts
+// any sign-in lockout and revokes every session of the user in a single
+// transaction. It returns
+// sql.ErrNoRows when the token was already used.
+func (repo *PasswordResetRepository) ResetPassword(ctx context.Context, resetID uuid.UUID, userID uuid.UUID, password string) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error resetting password: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_use = `
+		UPDATE "password_reset" SET used_at=now(), updated_at=now()
+		WHERE id=$1 AND used_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query_use, resetID)
+	if err != nil {
+		return fmt.Errorf("error using password reset: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error using password reset: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error using password reset: %w", sql.ErrNoRows)
+	}
+
+	const query_update_password = `
+		UPDATE "user" SET password=$2, failed_login_count=0, locked_until=NULL, updated_at=now()
+		WHERE id=$1
+	`
+
+	if _, err := tx.ExecContext(ctx, query_update_password, userID, password); err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+
+	const query_revoke_sessions = `
+		UPDATE "session" SET revoked_at=now(), updated_at=now()
+		WHERE user_id=$1 AND revoked_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, query_revoke_sessions, userID); err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error resetting password: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeExpiredPasswordResets deletes the password resets that expired before the given time.
+func (repo *PasswordResetRepository) PurgeExpiredPasswordResets(ctx context.Context, before time.Time) (int64, error) {
+	const query_purge = `
+		DELETE FROM "password_reset"
+		WHERE expired_at < $1
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_purge, before)
+	if err != nil {
+		return 0, fmt.Errorf("error purging password resets: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error purging password resets: %w", err)
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePasswordReset(t *testing.T) {
+	reset := &entity.PasswordReset{
+		UserID:    uuid.New(),
+		TokenHash: "hash",
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *PasswordResetRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *PasswordResetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "password_reset" SET used_at=now(), updated_at=now() WHERE user_id=$1 AND used_at IS NULL`).
+					WithArgs(reset.UserID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "password_reset" (user_id, token_hash, expired_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
+					WithArgs(reset.UserID, reset.TokenHash, reset.ExpiredAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, time.Now(), time.Now()))
+				mock.ExpectCommit()
+
+				record, err := repo.CreatePasswordReset(context.Background(), reset)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting password reset",
+			test: func(t *testing.T, repo *PasswordResetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "password_reset" SET used_at=now(), updated_at=now() WHERE user_id=$1 AND used_at IS NULL`).
+					WithArgs(reset.UserID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`INSERT INTO "password_reset" (user_id, token_hash, expired_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
+					WithArgs(reset.UserID, reset.TokenHash, reset.ExpiredAt).
+					WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+
+				_, err := repo.CreatePasswordReset(context.Background(), reset)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewPasswordResetRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	resetID := uuid.New()
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *PasswordResetRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *PasswordResetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "password_reset" SET used_at=now(), updated_at=now() WHERE id=$1 AND used_at IS NULL`).
+					WithArgs(resetID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "user" SET password=$2, failed_login_count=0, locked_until=NULL, updated_at=now() WHERE id=$1`).
+					WithArgs(userID, "new-hash").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=now(), updated_at=now() WHERE user_id=$1 AND revoked_at IS NULL`).
+					WithArgs(userID).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+
+				err := repo.ResetPassword(context.Background(), resetID, userID, "new-hash")
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "token already used",
+			test: func(t *testing.T, repo *PasswordResetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "password_reset" SET used_at=now(), updated_at=now() WHERE id=$1 AND used_at IS NULL`).
+					WithArgs(resetID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+
+				err := repo.ResetPassword(context.Background(), resetID, userID, "new-hash")
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewPasswordResetRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestPurgeExpiredPasswordResets(t *testing.T) {
+	before := time.Now().UTC()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *PasswordResetRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *PasswordResetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "password_reset" WHERE expired_at < $1`).
+					WithArgs(before).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				purged, err := repo.PurgeExpiredPasswordResets(context.Background(), before)
+				require.NoError(t, err)
+				require.Equal(t, int64(2), purged)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed purging password resets",
+			test: func(t *testing.T, repo *PasswordResetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "password_reset" WHERE expired_at < $1`).
+					WithArgs(before).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.PurgeExpiredPasswordResets(context.Background(), before)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewPasswordResetRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
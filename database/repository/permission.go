@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PermissionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPermissionRepository(db *sqlx.DB) *PermissionRepository {
+	return &PermissionRepository{
+		db: db,
+	}
+}
+
+func (repo *PermissionRepository) GetPermission(ctx context.Context, id uuid.UUID) (*entity.Permission, error) {
+	var r entity.Permission
+
+	const query_find_one = `
+		SELECT * FROM "permission"
+		WHERE id=$1
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_one, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting permission: %w", notFound("permission", err))
+	}
+
+	return &r, nil
+}
+
+func (repo *PermissionRepository) ListPermissions(ctx context.Context) ([]entity.Permission, error) {
+	var permissions []entity.Permission
+
+	const query_find_all = `
+		SELECT * FROM "permission"
+		ORDER BY name
+	`
+
+	err := repo.db.SelectContext(ctx, &permissions, query_find_all)
+	if err != nil {
+		return nil, fmt.Errorf("error listing permissions: %v", err)
+	}
+
+	return permissions, nil
+}
+
+func (repo *PermissionRepository) ListRolePermissions(ctx context.Context, roleID uuid.UUID) ([]entity.Permission, error) {
+	var permissions []entity.Permission
+
+	const query_find_by_role = `
+		SELECT p.* FROM "permission" p
+		JOIN "role_permission" rp ON rp.permission_id=p.id
+		WHERE rp.role_id=$1
+		ORDER BY p.name
+	`
+
+	err := repo.db.SelectContext(ctx, &permissions, query_find_by_role, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing role permissions: %v", err)
+	}
+
+	return permissions, nil
+}
+
+// ListPermissionNamesByRole returns only the names of the role's permissions,
+// e.g. "project:write".
+func (repo *PermissionRepository) ListPermissionNamesByRole(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	var names []string
+
+	const query_find_names = `
+		SELECT p.name FROM "permission" p
+		JOIN "role_permission" rp ON rp.permission_id=p.id
+		WHERE rp.role_id=$1
+	`
+
+	err := repo.db.SelectContext(ctx, &names, query_find_names, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing role permissions: %v", err)
+	}
+
+	return names, nil
+}
+
+func (repo *PermissionRepository) HasPermission(ctx context.Context, roleID uuid.UUID, name string) (bool, error) {
+	var exists bool
+
+	const query_has_permission = `
+		SELECT EXISTS (
+			SELECT 1 FROM "role_permission" rp
+			JOIN "permission" p ON p.id=rp.permission_id
+			WHERE rp.role_id=$1 AND p.name=$2
+		)
+	`
+
+	err := repo.db.GetContext(ctx, &exists, query_has_permission, roleID, name)
+	if err != nil {
+		return false, fmt.Errorf("error checking role permission: %v", err)
+	}
+
+	return exists, nil
+}
+
+// GrantPermission links the permission to the role. Granting a permission the
+// role already has is a no-op.
+func (repo *PermissionRepository) GrantPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	const query_insert = `
+		INSERT INTO "role_permission" (role_id, permission_id)
+		VALUES ($1, $2)
+		ON CONFLICT (role_id, permission_id) DO NOTHING
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_insert, roleID, permissionID)
+	if err != nil {
+		return fmt.Errorf("error granting permission: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *PermissionRepository) RevokePermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	const query_delete = `
+		DELETE FROM "role_permission"
+		WHERE role_id=$1 AND permission_id=$2
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_delete, roleID, permissionID)
+	if err != nil {
+		return fmt.Errorf("error revoking permission: %v", err)
+	}
+
+	return nil
+}
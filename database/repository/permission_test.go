@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/pkg/apperror"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPermission(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *PermissionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "description"}).
+					AddRow(expectedID, time.Now(), time.Now(), "role:read", nil)
+
+				mock.ExpectQuery(`SELECT * FROM "permission" WHERE id=$1`).
+					WithArgs(expectedID).
+					WillReturnRows(rows)
+
+				record, err := repo.GetPermission(context.Background(), expectedID)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, "role:read", record.Name)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "permission not found",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "permission" WHERE id=$1`).
+					WithArgs(expectedID).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetPermission(context.Background(), expectedID)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+				require.ErrorIs(t, err, apperror.ErrNotFound)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewPermissionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestListPermissionNamesByRole(t *testing.T) {
+	roleID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *PermissionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT p.name FROM "permission" p JOIN "role_permission" rp ON rp.permission_id=p.id WHERE rp.role_id=$1`).
+					WithArgs(roleID).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("project:write").AddRow("timesheet:approve"))
+
+				names, err := repo.ListPermissionNamesByRole(context.Background(), roleID)
+				require.NoError(t, err)
+				require.Equal(t, []string{"project:write", "timesheet:approve"}, names)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed listing role permissions",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT p.name FROM "permission" p JOIN "role_permission" rp ON rp.permission_id=p.id WHERE rp.role_id=$1`).
+					WithArgs(roleID).
+					WillReturnError(fmt.Errorf("error listing role permissions"))
+
+				_, err := repo.ListPermissionNamesByRole(context.Background(), roleID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewPermissionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	roleID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *PermissionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS ( SELECT 1 FROM "role_permission" rp JOIN "permission" p ON p.id=rp.permission_id WHERE rp.role_id=$1 AND p.name=$2 )`).
+					WithArgs(roleID, "timesheet:approve").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+				granted, err := repo.HasPermission(context.Background(), roleID, "timesheet:approve")
+				require.NoError(t, err)
+				require.False(t, granted)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed checking role permission",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS ( SELECT 1 FROM "role_permission" rp JOIN "permission" p ON p.id=rp.permission_id WHERE rp.role_id=$1 AND p.name=$2 )`).
+					WithArgs(roleID, "timesheet:approve").
+					WillReturnError(fmt.Errorf("error checking role permission"))
+
+				_, err := repo.HasPermission(context.Background(), roleID, "timesheet:approve")
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewPermissionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestGrantPermission(t *testing.T) {
+	roleID := uuid.New()
+	permissionID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *PermissionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO "role_permission" (role_id, permission_id) VALUES ($1, $2) ON CONFLICT (role_id, permission_id) DO NOTHING`).
+					WithArgs(roleID, permissionID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				err := repo.GrantPermission(context.Background(), roleID, permissionID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed granting permission",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO "role_permission" (role_id, permission_id) VALUES ($1, $2) ON CONFLICT (role_id, permission_id) DO NOTHING`).
+					WithArgs(roleID, permissionID).
+					WillReturnError(fmt.Errorf("error granting permission"))
+
+				err := repo.GrantPermission(context.Background(), roleID, permissionID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewPermissionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestRevokePermission(t *testing.T) {
+	roleID := uuid.New()
+	permissionID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *PermissionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "role_permission" WHERE role_id=$1 AND permission_id=$2`).
+					WithArgs(roleID, permissionID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				err := repo.RevokePermission(context.Background(), roleID, permissionID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed revoking permission",
+			test: func(t *testing.T, repo *PermissionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "role_permission" WHERE role_id=$1 AND permission_id=$2`).
+					WithArgs(roleID, permissionID).
+					WillReturnError(fmt.Errorf("error revoking permission"))
+
+				err := repo.RevokePermission(context.Background(), roleID, permissionID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewPermissionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
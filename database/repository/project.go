@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"gofi/database/entity"
 	"time"
@@ -33,7 +34,7 @@ func (repo *ProjectRepository) CreateProject(ctx context.Context, r *entity.Proj
 		RETURNING id, created_at, updated_at
 	`
 
-	err := repo.db.QueryRowContext(ctx, query_insert, r.Name).
+	err := repo.db.QueryRowContext(ctx, query_insert, r.OwnerID, r.Name, r.Description).
 		Scan(&lastInsertID, &createdAt, &updatedAt)
 
 	if err != nil {
@@ -51,31 +52,151 @@ func (repo *ProjectRepository) GetProject(ctx context.Context, id uuid.UUID) (*e
 	var r entity.Project
 
 	const query_find_one = `
-		SELECT * FROM "project" 
-		WHERE id=$1
+		SELECT * FROM "project"
+		WHERE id=$1 AND deleted_at IS NULL
 	`
 
 	err := repo.db.GetContext(ctx, &r, query_find_one, id)
 	if err != nil {
-		return nil, fmt.Errorf("error getting project: %v", err)
+		return nil, fmt.Errorf("error getting project: %w", notFound("project", err))
 	}
 
 	return &r, nil
 }
 
-func (repo *ProjectRepository) ListProjects(ctx context.Context) ([]entity.Project, error) {
+// projectColumns are the project fields that can be sorted and filtered on.
+var projectColumns = columns{
+	"id":          {expr: "p.id", filter: filterUUID},
+	"created_at":  {expr: "p.created_at"},
+	"updated_at":  {expr: "p.updated_at"},
+	"owner_id":    {expr: "p.owner_id", filter: filterUUID},
+	"name":        {expr: "p.name", filter: filterSearch},
+	"description": {expr: "p.description", filter: filterSearch},
+}
+
+// ListProjects lists a page of the projects along with the number of projects
+// matching.
+func (repo *ProjectRepository) ListProjects(ctx context.Context, opts entity.QueryOptions) ([]entity.Project, int, error) {
 	var projects []entity.Project
+	var total int
 
-	const query_find_all = `
-		SELECT * FROM "project"
+	query_find_all, query_count, args, err := listQuery(`"project" p`, []string{"p.deleted_at IS NULL"}, nil, opts, projectColumns, "name")
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing projects: %w", err)
+	}
+
+	err = repo.db.GetContext(ctx, &total, query_count, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting projects: %v", err)
+	}
+
+	err = repo.db.SelectContext(ctx, &projects, query_find_all, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing projects: %v", err)
+	}
+
+	return projects, total, nil
+}
+
+// ListProjectsByUser lists a page of the projects the user owns or is a member
+// of. With withDeleted, meant for those who can purge projects, it lists every
+// project instead, the soft deleted ones included. Offset pages count the
+// projects matching, keyset pages link to the pages around them.
+func (repo *ProjectRepository) ListProjectsByUser(ctx context.Context, userID uuid.UUID, withDeleted bool, opts entity.QueryOptions) ([]entity.Project, entity.Page, error) {
+	var projects []entity.Project
+	var page entity.Page
+
+	conds := []string{
+		`($2 OR p.owner_id=$1 OR EXISTS (SELECT 1 FROM "project_member" m WHERE m.project_id=p.id AND m.user_id=$1))`,
+		"($2 OR p.deleted_at IS NULL)",
+	}
+
+	query_find_by_user, query_count, args, err := listQuery(`"project" p`, conds, []interface{}{userID, withDeleted}, opts, projectColumns, "name")
+	if err != nil {
+		return nil, page, fmt.Errorf("error listing projects: %w", err)
+	}
+
+	if !opts.Keyset {
+		err = repo.db.GetContext(ctx, &page.Total, query_count, args...)
+		if err != nil {
+			return nil, page, fmt.Errorf("error counting projects: %v", err)
+		}
+	}
+
+	err = repo.db.SelectContext(ctx, &projects, query_find_by_user, args...)
+	if err != nil {
+		return nil, page, fmt.Errorf("error listing projects: %v", err)
+	}
+
+	if opts.Keyset {
+		projects, page = keysetPage(projects, opts, func(p entity.Project) entity.Cursor {
+			return entity.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+		})
+	}
+
+	return projects, page, nil
+}
+
+func (repo *ProjectRepository) IsProjectMember(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) (bool, error) {
+	var exists bool
+
+	const query_is_member = `
+		SELECT EXISTS (SELECT 1 FROM "project_member" WHERE project_id=$1 AND user_id=$2)
+	`
+
+	err := repo.db.GetContext(ctx, &exists, query_is_member, projectID, userID)
+	if err != nil {
+		return false, fmt.Errorf("error checking project member: %v", err)
+	}
+
+	return exists, nil
+}
+
+func (repo *ProjectRepository) ListProjectMembers(ctx context.Context, projectID uuid.UUID) ([]entity.ProjectMember, error) {
+	var members []entity.ProjectMember
+
+	const query_find_members = `
+		SELECT * FROM "project_member"
+		WHERE project_id=$1
+	`
+
+	err := repo.db.SelectContext(ctx, &members, query_find_members, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing project members: %v", err)
+	}
+
+	return members, nil
+}
+
+func (repo *ProjectRepository) AddProjectMember(ctx context.Context, m *entity.ProjectMember) (*entity.ProjectMember, error) {
+	const query_insert = `
+		INSERT INTO "project_member" (project_id, user_id)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := repo.db.QueryRowContext(ctx, query_insert, m.ProjectID, m.UserID).
+		Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting project member: %w", err)
+	}
+
+	return m, nil
+}
+
+func (repo *ProjectRepository) RemoveProjectMember(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) error {
+	const query_delete = `
+		DELETE FROM "project_member"
+		WHERE project_id=$1 AND user_id=$2
 	`
 
-	err := repo.db.SelectContext(ctx, &projects, query_find_all)
+	_, err := repo.db.ExecContext(ctx, query_delete, projectID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("error listing projects: %v", err)
+		return fmt.Errorf("error deleting project member: %v", err)
 	}
 
-	return projects, nil
+	return nil
 }
 
 func (repo *ProjectRepository) UpdateProject(ctx context.Context, r *entity.Project) (*entity.Project, error) {
@@ -92,15 +213,71 @@ func (repo *ProjectRepository) UpdateProject(ctx context.Context, r *entity.Proj
 	return r, nil
 }
 
-func (repo *ProjectRepository) DeleteProject(ctx context.Context, id uuid.UUID) error {
+// DeleteProject soft deletes the project of the owner, or of anyone when
+// anyOwner is set. It returns sql.ErrNoRows when there is no such project or
+// it is already deleted.
+func (repo *ProjectRepository) DeleteProject(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, anyOwner bool) error {
 	const query_delete = `
-		DELETE FROM "project" 
+		UPDATE "project" SET deleted_at=now(), updated_at=now()
+		WHERE id=$1 AND ($3 OR owner_id=$2) AND deleted_at IS NULL
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_delete, id, ownerID, anyOwner)
+	if err != nil {
+		return fmt.Errorf("error deleting project: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting project: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error deleting project: %w", notFound("project", sql.ErrNoRows))
+	}
+
+	return nil
+}
+
+// RestoreProject brings back a soft deleted project of the owner, or of anyone
+// when anyOwner is set.
+func (repo *ProjectRepository) RestoreProject(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, anyOwner bool) (*entity.Project, error) {
+	var r entity.Project
+
+	const query_restore = `
+		UPDATE "project" SET deleted_at=NULL, updated_at=now()
+		WHERE id=$1 AND ($3 OR owner_id=$2) AND deleted_at IS NOT NULL
+		RETURNING *
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_restore, id, ownerID, anyOwner)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring project: %w", notFound("project", err))
+	}
+
+	return &r, nil
+}
+
+// ForceDeleteProject permanently deletes the project, whether soft deleted or
+// not. Its members go with it.
+func (repo *ProjectRepository) ForceDeleteProject(ctx context.Context, id uuid.UUID) error {
+	const query_force_delete = `
+		DELETE FROM "project"
 		WHERE id=$1
 	`
 
-	_, err := repo.db.ExecContext(ctx, query_delete, id)
+	result, err := repo.db.ExecContext(ctx, query_force_delete, id)
 	if err != nil {
-		return fmt.Errorf("error deleting project: %v", err)
+		return fmt.Errorf("error deleting project: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting project: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error deleting project: %w", notFound("project", sql.ErrNoRows))
 	}
 
 	return nil
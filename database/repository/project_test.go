@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"gofi/database/entity"
+	"gofi/pkg/apperror"
 	"testing"
 	"time"
 
@@ -34,7 +36,7 @@ func TestCreateProject(t *testing.T) {
 				expectedUpdatedAt := time.Now()
 
 				mock.ExpectQuery(`INSERT INTO "project" (owner_id, name, description) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
-					WithArgs(p.Name).
+					WithArgs(p.OwnerID, p.Name, p.Description).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
 
@@ -53,7 +55,7 @@ func TestCreateProject(t *testing.T) {
 			name: "failed inserting project",
 			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`INSERT INTO "project" (owner_id, name, description) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
-					WithArgs(p.Name).
+					WithArgs(p.OwnerID, p.Name, p.Description).
 					WillReturnError(fmt.Errorf("error inserting project"))
 
 				_, err := repo.CreateProject(context.Background(), p)
@@ -94,7 +96,7 @@ func TestGetProject(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "owner_id", "name", "description"}).
 					AddRow(expectedID, p.CreatedAt, p.UpdatedAt, p.DeletedAt, p.OwnerID, p.Name, p.Description)
 
-				mock.ExpectQuery(`SELECT * FROM "project" WHERE id=$1`).
+				mock.ExpectQuery(`SELECT * FROM "project" WHERE id=$1 AND deleted_at IS NULL`).
 					WithArgs(expectedID).
 					WillReturnRows(rows)
 
@@ -109,7 +111,7 @@ func TestGetProject(t *testing.T) {
 		{
 			name: "failed getting project",
 			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT * FROM "project" WHERE id=$1`).
+				mock.ExpectQuery(`SELECT * FROM "project" WHERE id=$1 AND deleted_at IS NULL`).
 					WithArgs(expectedID).
 					WillReturnError(fmt.Errorf("error getting project"))
 
@@ -151,11 +153,14 @@ func TestListProjects(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "owner_id", "name", "description"}).
 					AddRow(expectedID, p.CreatedAt, p.UpdatedAt, p.DeletedAt, p.OwnerID, p.Name, p.Description)
 
-				mock.ExpectQuery(`SELECT * FROM "project"`).WillReturnRows(rows)
+				mock.ExpectQuery(`SELECT count(*) FROM "project" p WHERE p.deleted_at IS NULL`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT * FROM "project" p WHERE p.deleted_at IS NULL ORDER BY p.name ASC, p.id ASC LIMIT 20 OFFSET 0`).WillReturnRows(rows)
 
-				records, err := repo.ListProjects(context.Background())
+				records, total, err := repo.ListProjects(context.Background(), entity.QueryOptions{Page: 1, PageSize: 20})
 				require.NoError(t, err)
 				require.Len(t, records, 1)
+				require.Equal(t, 1, total)
 
 				err = mock.ExpectationsWereMet()
 				require.NoError(t, err)
@@ -164,9 +169,11 @@ func TestListProjects(t *testing.T) {
 		{
 			name: "failed querying project",
 			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT * FROM "project"`).WillReturnError(fmt.Errorf("error querying project"))
+				mock.ExpectQuery(`SELECT count(*) FROM "project" p WHERE p.deleted_at IS NULL`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT * FROM "project" p WHERE p.deleted_at IS NULL ORDER BY p.name ASC, p.id ASC LIMIT 20 OFFSET 0`).WillReturnError(fmt.Errorf("error querying project"))
 
-				_, err := repo.ListProjects(context.Background())
+				_, _, err := repo.ListProjects(context.Background(), entity.QueryOptions{Page: 1, PageSize: 20})
 				require.Error(t, err)
 
 				err = mock.ExpectationsWereMet()
@@ -211,7 +218,7 @@ func TestUpdateProject(t *testing.T) {
 				expectedUpdatedAt := time.Now()
 
 				mock.ExpectQuery(`INSERT INTO "project" (owner_id, name, description) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
-					WithArgs(p.Name).
+					WithArgs(p.OwnerID, p.Name, p.Description).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
 
@@ -260,6 +267,7 @@ func TestUpdateProject(t *testing.T) {
 
 func TestDeleteProject(t *testing.T) {
 	expectedID := uuid.New()
+	ownerID := uuid.New()
 
 	tcs := []struct {
 		name string
@@ -268,11 +276,25 @@ func TestDeleteProject(t *testing.T) {
 		{
 			name: "success",
 			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`DELETE FROM "project" WHERE id=$1`).
-					WithArgs(expectedID).
+				mock.ExpectExec(`UPDATE "project" SET deleted_at=now(), updated_at=now() WHERE id=$1 AND ($3 OR owner_id=$2) AND deleted_at IS NULL`).
+					WithArgs(expectedID, ownerID, false).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
-				err := repo.DeleteProject(context.Background(), expectedID)
+				err := repo.DeleteProject(context.Background(), expectedID, ownerID, false)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "project of another owner",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "project" SET deleted_at=now(), updated_at=now() WHERE id=$1 AND ($3 OR owner_id=$2) AND deleted_at IS NULL`).
+					WithArgs(expectedID, ownerID, true).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				err := repo.DeleteProject(context.Background(), expectedID, ownerID, true)
 				require.NoError(t, err)
 
 				err = mock.ExpectationsWereMet()
@@ -282,11 +304,204 @@ func TestDeleteProject(t *testing.T) {
 		{
 			name: "failed deleting project",
 			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`DELETE FROM "project" WHERE id=$1`).
-					WithArgs(expectedID).
+				mock.ExpectExec(`UPDATE "project" SET deleted_at=now(), updated_at=now() WHERE id=$1 AND ($3 OR owner_id=$2) AND deleted_at IS NULL`).
+					WithArgs(expectedID, ownerID, false).
 					WillReturnError(fmt.Errorf("error deleting project"))
 
-				err := repo.DeleteProject(context.Background(), expectedID)
+				err := repo.DeleteProject(context.Background(), expectedID, ownerID, false)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewProjectRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestListProjectsByUser(t *testing.T) {
+	userID := uuid.New()
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *ProjectRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "owner_id", "name", "description"}).
+					AddRow(expectedID, time.Now(), time.Now(), nil, userID, "Test Project", "Test Description")
+
+				mock.ExpectQuery(`SELECT count(*) FROM "project" p WHERE ($2 OR p.owner_id=$1 OR EXISTS (SELECT 1 FROM "project_member" m WHERE m.project_id=p.id AND m.user_id=$1)) AND ($2 OR p.deleted_at IS NULL) AND p.name ILIKE '%' || $3 || '%' ESCAPE '\'`).
+					WithArgs(userID, false, "test").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT * FROM "project" p WHERE ($2 OR p.owner_id=$1 OR EXISTS (SELECT 1 FROM "project_member" m WHERE m.project_id=p.id AND m.user_id=$1)) AND ($2 OR p.deleted_at IS NULL) AND p.name ILIKE '%' || $3 || '%' ESCAPE '\' ORDER BY p.updated_at DESC, p.id ASC LIMIT 20 OFFSET 0`).
+					WithArgs(userID, false, "test").
+					WillReturnRows(rows)
+
+				opts := entity.QueryOptions{Page: 1, PageSize: 20, Sort: "-updated_at", Filters: map[string]string{"name": "test"}}
+				records, page, err := repo.ListProjectsByUser(context.Background(), userID, false, opts)
+				require.NoError(t, err)
+				require.Len(t, records, 1)
+				require.Equal(t, 1, page.Total)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "next keyset page",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				cursor := &entity.Cursor{CreatedAt: time.Now(), ID: uuid.New()}
+				createdAt := time.Now().Add(-time.Hour)
+
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "owner_id", "name", "description"}).
+					AddRow(expectedID, createdAt, createdAt, nil, userID, "Test Project", "Test Description")
+
+				mock.ExpectQuery(`SELECT * FROM "project" p WHERE ($2 OR p.owner_id=$1 OR EXISTS (SELECT 1 FROM "project_member" m WHERE m.project_id=p.id AND m.user_id=$1)) AND ($2 OR p.deleted_at IS NULL) AND p.created_at<=$3 AND (p.created_at, p.id) < ($3, $4) ORDER BY p.created_at DESC, p.id DESC LIMIT 21`).
+					WithArgs(userID, false, cursor.CreatedAt, cursor.ID).
+					WillReturnRows(rows)
+
+				records, page, err := repo.ListProjectsByUser(context.Background(), userID, false, entity.QueryOptions{PageSize: 20, Keyset: true, Cursor: cursor})
+				require.NoError(t, err)
+				require.Len(t, records, 1)
+				require.Nil(t, page.Next)
+				require.Equal(t, &entity.Cursor{CreatedAt: createdAt, ID: expectedID, Prev: true}, page.Prev)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "sorted keyset page",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				_, _, err := repo.ListProjectsByUser(context.Background(), userID, false, entity.QueryOptions{PageSize: 20, Sort: "name", Keyset: true})
+				require.ErrorIs(t, err, ErrInvalidQuery)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed querying project",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count(*) FROM "project" p WHERE ($2 OR p.owner_id=$1 OR EXISTS (SELECT 1 FROM "project_member" m WHERE m.project_id=p.id AND m.user_id=$1)) AND ($2 OR p.deleted_at IS NULL)`).
+					WithArgs(userID, false).
+					WillReturnError(fmt.Errorf("error querying project"))
+
+				_, _, err := repo.ListProjectsByUser(context.Background(), userID, false, entity.QueryOptions{Page: 1, PageSize: 20})
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewProjectRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestIsProjectMember(t *testing.T) {
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *ProjectRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS (SELECT 1 FROM "project_member" WHERE project_id=$1 AND user_id=$2)`).
+					WithArgs(projectID, userID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+				member, err := repo.IsProjectMember(context.Background(), projectID, userID)
+				require.NoError(t, err)
+				require.True(t, member)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed checking project member",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS (SELECT 1 FROM "project_member" WHERE project_id=$1 AND user_id=$2)`).
+					WithArgs(projectID, userID).
+					WillReturnError(fmt.Errorf("error checking project member"))
+
+				_, err := repo.IsProjectMember(context.Background(), projectID, userID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewProjectRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestAddProjectMember(t *testing.T) {
+	m := &entity.ProjectMember{
+		ProjectID: uuid.New(),
+		UserID:    uuid.New(),
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *ProjectRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "project_member" (project_id, user_id) VALUES ($1, $2) RETURNING id, created_at, updated_at`).
+					WithArgs(m.ProjectID, m.UserID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, time.Now(), time.Now()))
+
+				record, err := repo.AddProjectMember(context.Background(), m)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting project member",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "project_member" (project_id, user_id) VALUES ($1, $2) RETURNING id, created_at, updated_at`).
+					WithArgs(m.ProjectID, m.UserID).
+					WillReturnError(fmt.Errorf("error inserting project member"))
+
+				_, err := repo.AddProjectMember(context.Background(), m)
 				require.Error(t, err)
 
 				err = mock.ExpectationsWereMet()
@@ -304,3 +519,104 @@ func TestDeleteProject(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreProject(t *testing.T) {
+	expectedID := uuid.New()
+	ownerID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *ProjectRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "owner_id", "name", "description"}).
+					AddRow(expectedID, time.Now(), time.Now(), nil, ownerID, "Website", "Company website")
+
+				mock.ExpectQuery(`UPDATE "project" SET deleted_at=NULL, updated_at=now() WHERE id=$1 AND ($3 OR owner_id=$2) AND deleted_at IS NOT NULL RETURNING *`).
+					WithArgs(expectedID, ownerID, false).
+					WillReturnRows(rows)
+
+				record, err := repo.RestoreProject(context.Background(), expectedID, ownerID, false)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "project not deleted or not owned",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE "project" SET deleted_at=NULL, updated_at=now() WHERE id=$1 AND ($3 OR owner_id=$2) AND deleted_at IS NOT NULL RETURNING *`).
+					WithArgs(expectedID, ownerID, false).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.RestoreProject(context.Background(), expectedID, ownerID, false)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+				require.ErrorIs(t, err, apperror.ErrNotFound)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewProjectRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestForceDeleteProject(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *ProjectRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "project" WHERE id=$1`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				err := repo.ForceDeleteProject(context.Background(), expectedID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "project not found",
+			test: func(t *testing.T, repo *ProjectRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "project" WHERE id=$1`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				err := repo.ForceDeleteProject(context.Background(), expectedID)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+				require.ErrorIs(t, err, apperror.ErrNotFound)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewProjectRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
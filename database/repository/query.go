@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gofi/database/entity"
+	"gofi/pkg/apperror"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidQuery is returned when the list options sort or filter on a field
+// the entity does not expose, or the filter value does not fit the column.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// invalidQuery describes what is wrong with the list options, as a
+// validation error matching ErrInvalidQuery.
+func invalidQuery(format string, args ...interface{}) error {
+	return apperror.Wrap(apperror.ErrValidation, fmt.Sprintf(format, args...), ErrInvalidQuery)
+}
+
+type filterKind int
+
+const (
+	// filterNone columns can only be sorted on
+	filterNone filterKind = iota
+	filterExact
+	filterSearch
+	filterUUID
+	filterBool
+	filterInt
+)
+
+// likeEscaper escapes the wildcards of LIKE patterns, so a search matches the
+// value literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// column is a field of an entity exposed to ?sort= and ?filter[field]=.
+type column struct {
+	expr   string
+	filter filterKind
+}
+
+// columns whitelists the fields of an entity by their public name. Every
+// entity exposes "id", which is used to break ties when sorting.
+type columns map[string]column
+
+// listQuery builds the query returning one page of rows from the table and
+// the query counting all the rows matching. The conditions are joined with
+// AND and their placeholders are bound to args, the filters of opts are added
+// after them. Keyset pages are delegated to keysetQuery.
+func listQuery(from string, conds []string, args []interface{}, opts entity.QueryOptions, cols columns, defaultSort string) (string, string, []interface{}, error) {
+	fields := make([]string, 0, len(opts.Filters))
+	for field := range opts.Filters {
+		fields = append(fields, field)
+	}
+	// keep the placeholders in a stable order
+	slices.Sort(fields)
+
+	for _, field := range fields {
+		value := opts.Filters[field]
+
+		col, ok := cols[field]
+		if !ok || col.filter == filterNone {
+			return "", "", nil, invalidQuery("cannot filter on %q", field)
+		}
+
+		var arg interface{} = value
+		switch col.filter {
+		case filterSearch:
+			arg = likeEscaper.Replace(value)
+		case filterUUID:
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return "", "", nil, invalidQuery("filter %q must be a uuid", field)
+			}
+			arg = id
+		case filterBool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return "", "", nil, invalidQuery("filter %q must be a boolean", field)
+			}
+			arg = b
+		case filterInt:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return "", "", nil, invalidQuery("filter %q must be a number", field)
+			}
+			arg = n
+		}
+
+		args = append(args, arg)
+		if col.filter == filterSearch {
+			conds = append(conds, fmt.Sprintf("%s ILIKE '%%' || $%d || '%%' ESCAPE '\\'", col.expr, len(args)))
+		} else {
+			conds = append(conds, fmt.Sprintf("%s=$%d", col.expr, len(args)))
+		}
+	}
+
+	if opts.Keyset {
+		return keysetQuery(from, conds, args, opts, cols)
+	}
+
+	sort := opts.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+
+	var order []string
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			field = field[1:]
+			direction = "DESC"
+		}
+
+		col, ok := cols[field]
+		if !ok {
+			return "", "", nil, invalidQuery("cannot sort on %q", field)
+		}
+		order = append(order, col.expr+" "+direction)
+	}
+	order = append(order, cols["id"].expr+" ASC")
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s%s ORDER BY %s LIMIT %d OFFSET %d", from, where, strings.Join(order, ", "), opts.PageSize, opts.Offset())
+	count := fmt.Sprintf("SELECT count(*) FROM %s%s", from, where)
+
+	return query, count, args, nil
+}
+
+// keysetQuery builds the query of a keyset page, which holds one row more
+// than the page size to tell whether there are rows past it. Pages going
+// backwards are read in ascending order and must be reversed, keysetPage
+// takes care of both.
+func keysetQuery(from string, conds []string, args []interface{}, opts entity.QueryOptions, cols columns) (string, string, []interface{}, error) {
+	if opts.Sort != "" {
+		return "", "", nil, invalidQuery("cursor pages cannot be sorted")
+	}
+
+	createdAt, id := cols["created_at"].expr, cols["id"].expr
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	count := fmt.Sprintf("SELECT count(*) FROM %s%s", from, where)
+
+	direction, operator := "DESC", "<"
+	if opts.Cursor != nil && opts.Cursor.Prev {
+		direction, operator = "ASC", ">"
+	}
+
+	if opts.Cursor != nil {
+		args = append(args, opts.Cursor.CreatedAt, opts.Cursor.ID)
+		// the bound on created_at alone lets the created_at index narrow the scan
+		conds = append(conds, fmt.Sprintf("%s%s=$%d AND (%s, %s) %s ($%d, $%d)", createdAt, operator, len(args)-1, createdAt, id, operator, len(args)-1, len(args)))
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s%s ORDER BY %s %s, %s %s LIMIT %d", from, where, createdAt, direction, id, direction, opts.PageSize+1)
+
+	return query, count, args, nil
+}
+
+// keysetPage trims the rows read by a keyset query to the page and links the
+// pages around it, key returns the cursor of a row.
+func keysetPage[T any](rows []T, opts entity.QueryOptions, key func(T) entity.Cursor) ([]T, entity.Page) {
+	var page entity.Page
+
+	more := len(rows) > opts.PageSize
+	if more {
+		rows = rows[:opts.PageSize]
+	}
+
+	backwards := opts.Cursor != nil && opts.Cursor.Prev
+	if backwards {
+		slices.Reverse(rows)
+	}
+
+	// an empty page still links back to where it was reached from
+	if len(rows) == 0 {
+		if opts.Cursor != nil {
+			back := *opts.Cursor
+			back.Prev = !back.Prev
+			if backwards {
+				page.Next = &back
+			} else {
+				page.Prev = &back
+			}
+		}
+		return rows, page
+	}
+
+	// going backwards, the page was reached from the one after it
+	if more || backwards {
+		next := key(rows[len(rows)-1])
+		page.Next = &next
+	}
+
+	if (backwards && more) || (!backwards && opts.Cursor != nil) {
+		prev := key(rows[0])
+		prev.Prev = true
+		page.Prev = &prev
+	}
+
+	return rows, page
+}
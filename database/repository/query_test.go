@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListQuery(t *testing.T) {
+	cols := columns{
+		"id":         {expr: "id", filter: filterUUID},
+		"created_at": {expr: "created_at"},
+		"name":       {expr: "name", filter: filterSearch},
+		"active":     {expr: "is_active", filter: filterBool},
+		"week":       {expr: "week", filter: filterInt},
+	}
+
+	cursor := entity.Cursor{CreatedAt: time.Now(), ID: uuid.New(), Prev: true}
+
+	tcs := []struct {
+		name  string
+		opts  entity.QueryOptions
+		query string
+		count string
+		args  []interface{}
+		err   bool
+	}{
+		{
+			name:  "default sort",
+			opts:  entity.QueryOptions{Page: 3, PageSize: 5},
+			query: `SELECT * FROM "thing" WHERE deleted_at IS NULL ORDER BY name ASC, id ASC LIMIT 5 OFFSET 10`,
+			count: `SELECT count(*) FROM "thing" WHERE deleted_at IS NULL`,
+		},
+		{
+			name:  "filters and sort",
+			opts:  entity.QueryOptions{Page: 1, PageSize: 5, Sort: "-week, created_at", Filters: map[string]string{"week": "31", "active": "false"}},
+			query: `SELECT * FROM "thing" WHERE deleted_at IS NULL AND is_active=$1 AND week=$2 ORDER BY week DESC, created_at ASC, id ASC LIMIT 5 OFFSET 0`,
+			count: `SELECT count(*) FROM "thing" WHERE deleted_at IS NULL AND is_active=$1 AND week=$2`,
+			args:  []interface{}{false, 31},
+		},
+		{
+			name:  "search matches wildcards literally",
+			opts:  entity.QueryOptions{Page: 1, PageSize: 5, Filters: map[string]string{"name": `50%_off\`}},
+			query: `SELECT * FROM "thing" WHERE deleted_at IS NULL AND name ILIKE '%' || $1 || '%' ESCAPE '\' ORDER BY name ASC, id ASC LIMIT 5 OFFSET 0`,
+			count: `SELECT count(*) FROM "thing" WHERE deleted_at IS NULL AND name ILIKE '%' || $1 || '%' ESCAPE '\'`,
+			args:  []interface{}{`50\%\_off\\`},
+		},
+		{
+			name:  "first keyset page",
+			opts:  entity.QueryOptions{PageSize: 5, Keyset: true, Filters: map[string]string{"active": "true"}},
+			query: `SELECT * FROM "thing" WHERE deleted_at IS NULL AND is_active=$1 ORDER BY created_at DESC, id DESC LIMIT 6`,
+			count: `SELECT count(*) FROM "thing" WHERE deleted_at IS NULL AND is_active=$1`,
+			args:  []interface{}{true},
+		},
+		{
+			name:  "previous keyset page",
+			opts:  entity.QueryOptions{PageSize: 5, Keyset: true, Cursor: &cursor},
+			query: `SELECT * FROM "thing" WHERE deleted_at IS NULL AND created_at>=$1 AND (created_at, id) > ($1, $2) ORDER BY created_at ASC, id ASC LIMIT 6`,
+			count: `SELECT count(*) FROM "thing" WHERE deleted_at IS NULL`,
+			args:  []interface{}{cursor.CreatedAt, cursor.ID},
+		},
+		{
+			name: "sorted keyset page",
+			opts: entity.QueryOptions{PageSize: 5, Keyset: true, Sort: "name"},
+			err:  true,
+		},
+		{
+			name: "unknown sort",
+			opts: entity.QueryOptions{Page: 1, PageSize: 5, Sort: "password"},
+			err:  true,
+		},
+		{
+			name: "sort only column",
+			opts: entity.QueryOptions{Page: 1, PageSize: 5, Filters: map[string]string{"created_at": "2024-01-01"}},
+			err:  true,
+		},
+		{
+			name: "invalid number",
+			opts: entity.QueryOptions{Page: 1, PageSize: 5, Filters: map[string]string{"week": "last"}},
+			err:  true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			query, count, args, err := listQuery(`"thing"`, []string{"deleted_at IS NULL"}, nil, tc.opts, cols, "name")
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidQuery)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.query, query)
+			require.Equal(t, tc.count, count)
+			require.Equal(t, tc.args, args)
+		})
+	}
+}
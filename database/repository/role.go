@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"gofi/database/entity"
 	"time"
@@ -28,12 +29,12 @@ func (repo *RoleRepository) CreateRole(ctx context.Context, r *entity.Role) (*en
 	)
 
 	const query_insert = `
-		INSERT INTO "role" (name) 
-		VALUES ($1)
+		INSERT INTO "role" (name, require_2fa)
+		VALUES ($1, $2)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := repo.db.QueryRowContext(ctx, query_insert, r.Name).
+	err := repo.db.QueryRowContext(ctx, query_insert, r.Name, r.Require2FA).
 		Scan(&lastInsertID, &createdAt, &updatedAt)
 
 	if err != nil {
@@ -51,36 +52,71 @@ func (repo *RoleRepository) GetRole(ctx context.Context, id uuid.UUID) (*entity.
 	var r entity.Role
 
 	const query_find_one = `
-		SELECT * FROM "role" 
-		WHERE id=$1
+		SELECT * FROM "role"
+		WHERE id=$1 AND deleted_at IS NULL
 	`
 
 	err := repo.db.GetContext(ctx, &r, query_find_one, id)
 	if err != nil {
-		return nil, fmt.Errorf("error getting role: %v", err)
+		return nil, fmt.Errorf("error getting role: %w", notFound("role", err))
 	}
 
 	return &r, nil
 }
 
-func (repo *RoleRepository) ListRoles(ctx context.Context) ([]entity.Role, error) {
-	var roles []entity.Role
+// GetRoleByName finds a role by its exact name.
+func (repo *RoleRepository) GetRoleByName(ctx context.Context, name string) (*entity.Role, error) {
+	var r entity.Role
 
-	const query_find_all = `
+	const query_find_one = `
 		SELECT * FROM "role"
+		WHERE name=$1 AND deleted_at IS NULL
 	`
 
-	err := repo.db.SelectContext(ctx, &roles, query_find_all)
+	err := repo.db.GetContext(ctx, &r, query_find_one, name)
 	if err != nil {
-		return nil, fmt.Errorf("error listing roles: %v", err)
+		return nil, fmt.Errorf("error getting role: %w", notFound("role", err))
 	}
 
-	return roles, nil
+	return &r, nil
+}
+
+// roleColumns are the role fields that can be sorted and filtered on.
+var roleColumns = columns{
+	"id":          {expr: "id", filter: filterUUID},
+	"created_at":  {expr: "created_at"},
+	"updated_at":  {expr: "updated_at"},
+	"name":        {expr: "name", filter: filterSearch},
+	"require_2fa": {expr: "require_2fa", filter: filterBool},
+}
+
+// ListRoles lists a page of the roles, including the soft deleted ones when
+// withDeleted is set, along with the number of roles matching.
+func (repo *RoleRepository) ListRoles(ctx context.Context, withDeleted bool, opts entity.QueryOptions) ([]entity.Role, int, error) {
+	var roles []entity.Role
+	var total int
+
+	query_find_all, query_count, args, err := listQuery(`"role"`, []string{"($1 OR deleted_at IS NULL)"}, []interface{}{withDeleted}, opts, roleColumns, "name")
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing roles: %w", err)
+	}
+
+	err = repo.db.GetContext(ctx, &total, query_count, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting roles: %v", err)
+	}
+
+	err = repo.db.SelectContext(ctx, &roles, query_find_all, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing roles: %v", err)
+	}
+
+	return roles, total, nil
 }
 
 func (repo *RoleRepository) UpdateRole(ctx context.Context, r *entity.Role) (*entity.Role, error) {
 	const query_update = `
-		UPDATE "role" SET name=:name, updated_at=:updated_at 
+		UPDATE "role" SET name=:name, require_2fa=:require_2fa, updated_at=:updated_at
 		WHERE id=:id
 	`
 
@@ -92,16 +128,85 @@ func (repo *RoleRepository) UpdateRole(ctx context.Context, r *entity.Role) (*en
 	return r, nil
 }
 
+// DeleteRole soft deletes the role. It returns sql.ErrNoRows when there is
+// no such role or it is already deleted.
 func (repo *RoleRepository) DeleteRole(ctx context.Context, id uuid.UUID) error {
 	const query_delete = `
-		DELETE FROM "role" 
+		UPDATE "role" SET deleted_at=now(), updated_at=now()
+		WHERE id=$1 AND deleted_at IS NULL
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_delete, id)
+	if err != nil {
+		return fmt.Errorf("error deleting role: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting role: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error deleting role: %w", notFound("role", sql.ErrNoRows))
+	}
+
+	return nil
+}
+
+// RestoreRole brings back a soft deleted role.
+func (repo *RoleRepository) RestoreRole(ctx context.Context, id uuid.UUID) (*entity.Role, error) {
+	var r entity.Role
+
+	const query_restore = `
+		UPDATE "role" SET deleted_at=NULL, updated_at=now()
+		WHERE id=$1 AND deleted_at IS NOT NULL
+		RETURNING *
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_restore, id)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring role: %w", notFound("role", err))
+	}
+
+	return &r, nil
+}
+
+// ForceDeleteRole permanently deletes the role, whether soft deleted or not.
+func (repo *RoleRepository) ForceDeleteRole(ctx context.Context, id uuid.UUID) error {
+	const query_force_delete = `
+		DELETE FROM "role"
 		WHERE id=$1
 	`
 
-	_, err := repo.db.ExecContext(ctx, query_delete, id)
+	result, err := repo.db.ExecContext(ctx, query_force_delete, id)
+	if err != nil {
+		return fmt.Errorf("error deleting role: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("error deleting role: %v", err)
+		return fmt.Errorf("error deleting role: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error deleting role: %w", notFound("role", sql.ErrNoRows))
 	}
 
 	return nil
 }
+
+// IsRoleInUse reports whether users that are not deleted still have the role.
+func (repo *RoleRepository) IsRoleInUse(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+
+	const query_in_use = `
+		SELECT EXISTS (SELECT 1 FROM "user" WHERE role_id=$1 AND deleted_at IS NULL)
+	`
+
+	err := repo.db.GetContext(ctx, &exists, query_in_use, id)
+	if err != nil {
+		return false, fmt.Errorf("error checking role users: %w", err)
+	}
+
+	return exists, nil
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"gofi/database/entity"
+	"gofi/pkg/apperror"
 	"testing"
 	"time"
 
@@ -31,8 +33,8 @@ func TestCreateRole(t *testing.T) {
 				expectedCreatedAt := time.Now()
 				expectedUpdatedAt := time.Now()
 
-				mock.ExpectQuery(`INSERT INTO "role" (name) VALUES ($1) RETURNING id, created_at, updated_at`).
-					WithArgs(r.Name).
+				mock.ExpectQuery(`INSERT INTO "role" (name, require_2fa) VALUES ($1, $2) RETURNING id, created_at, updated_at`).
+					WithArgs(r.Name, r.Require2FA).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
 
@@ -50,8 +52,8 @@ func TestCreateRole(t *testing.T) {
 		{
 			name: "failed inserting role",
 			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`INSERT INTO "role" (name) VALUES ($1) RETURNING id, created_at, updated_at`).
-					WithArgs(r.Name).
+				mock.ExpectQuery(`INSERT INTO "role" (name, require_2fa) VALUES ($1, $2) RETURNING id, created_at, updated_at`).
+					WithArgs(r.Name, r.Require2FA).
 					WillReturnError(fmt.Errorf("error inserting role"))
 
 				_, err := repo.CreateRole(context.Background(), r)
@@ -90,7 +92,7 @@ func TestGetRole(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "name"}).
 					AddRow(expectedID, r.CreatedAt, r.UpdatedAt, r.DeletedAt, r.Name)
 
-				mock.ExpectQuery(`SELECT * FROM "role" WHERE id=$1`).
+				mock.ExpectQuery(`SELECT * FROM "role" WHERE id=$1 AND deleted_at IS NULL`).
 					WithArgs(expectedID).
 					WillReturnRows(rows)
 
@@ -105,7 +107,7 @@ func TestGetRole(t *testing.T) {
 		{
 			name: "failed getting role",
 			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT * FROM "role" WHERE id=$1`).
+				mock.ExpectQuery(`SELECT * FROM "role" WHERE id=$1 AND deleted_at IS NULL`).
 					WithArgs(expectedID).
 					WillReturnError(fmt.Errorf("error getting role"))
 
@@ -128,6 +130,62 @@ func TestGetRole(t *testing.T) {
 	}
 }
 
+func TestGetRoleByName(t *testing.T) {
+	r := &entity.Role{
+		Name: "Admin",
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *RoleRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "name"}).
+					AddRow(expectedID, r.CreatedAt, r.UpdatedAt, r.DeletedAt, r.Name)
+
+				mock.ExpectQuery(`SELECT * FROM "role" WHERE name=$1 AND deleted_at IS NULL`).
+					WithArgs(r.Name).
+					WillReturnRows(rows)
+
+				record, err := repo.GetRoleByName(context.Background(), r.Name)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "role not found",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "role" WHERE name=$1 AND deleted_at IS NULL`).
+					WithArgs(r.Name).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetRoleByName(context.Background(), r.Name)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+				require.ErrorIs(t, err, apperror.ErrNotFound)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewRoleRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
 func TestListRoles(t *testing.T) {
 	r := &entity.Role{
 		Name: "Test Role",
@@ -145,11 +203,47 @@ func TestListRoles(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "name"}).
 					AddRow(expectedID, r.CreatedAt, r.UpdatedAt, r.DeletedAt, r.Name)
 
-				mock.ExpectQuery(`SELECT * FROM "role"`).WillReturnRows(rows)
+				mock.ExpectQuery(`SELECT count(*) FROM "role" WHERE ($1 OR deleted_at IS NULL)`).WithArgs(false).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+				mock.ExpectQuery(`SELECT * FROM "role" WHERE ($1 OR deleted_at IS NULL) ORDER BY name ASC, id ASC LIMIT 20 OFFSET 20`).WithArgs(false).WillReturnRows(rows)
 
-				records, err := repo.ListRoles(context.Background())
+				records, total, err := repo.ListRoles(context.Background(), false, entity.QueryOptions{Page: 2, PageSize: 20})
 				require.NoError(t, err)
 				require.Len(t, records, 1)
+				require.Equal(t, 21, total)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "success with filter and sort",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "name"}).
+					AddRow(expectedID, r.CreatedAt, r.UpdatedAt, r.DeletedAt, r.Name)
+
+				opts := entity.QueryOptions{Page: 1, PageSize: 10, Sort: "-created_at", Filters: map[string]string{"name": "test", "require_2fa": "true"}}
+
+				mock.ExpectQuery(`SELECT count(*) FROM "role" WHERE ($1 OR deleted_at IS NULL) AND name ILIKE '%' || $2 || '%' ESCAPE '\' AND require_2fa=$3`).WithArgs(true, "test", true).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT * FROM "role" WHERE ($1 OR deleted_at IS NULL) AND name ILIKE '%' || $2 || '%' ESCAPE '\' AND require_2fa=$3 ORDER BY created_at DESC, id ASC LIMIT 10 OFFSET 0`).WithArgs(true, "test", true).WillReturnRows(rows)
+
+				records, total, err := repo.ListRoles(context.Background(), true, opts)
+				require.NoError(t, err)
+				require.Len(t, records, 1)
+				require.Equal(t, 1, total)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "unknown filter",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				opts := entity.QueryOptions{Page: 1, PageSize: 10, Filters: map[string]string{"deleted_at": "now"}}
+
+				_, _, err := repo.ListRoles(context.Background(), false, opts)
+				require.ErrorIs(t, err, ErrInvalidQuery)
 
 				err = mock.ExpectationsWereMet()
 				require.NoError(t, err)
@@ -158,9 +252,11 @@ func TestListRoles(t *testing.T) {
 		{
 			name: "failed querying role",
 			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT * FROM "role"`).WillReturnError(fmt.Errorf("error querying role"))
+				mock.ExpectQuery(`SELECT count(*) FROM "role" WHERE ($1 OR deleted_at IS NULL)`).WithArgs(false).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT * FROM "role" WHERE ($1 OR deleted_at IS NULL) ORDER BY name ASC, id ASC LIMIT 20 OFFSET 0`).WithArgs(false).WillReturnError(fmt.Errorf("error querying role"))
 
-				_, err := repo.ListRoles(context.Background())
+				_, _, err := repo.ListRoles(context.Background(), false, entity.QueryOptions{Page: 1, PageSize: 20})
 				require.Error(t, err)
 
 				err = mock.ExpectationsWereMet()
@@ -202,8 +298,8 @@ func TestUpdateRole(t *testing.T) {
 				expectedCreatedAt := time.Now()
 				expectedUpdatedAt := time.Now()
 
-				mock.ExpectQuery(`INSERT INTO "role" (name) VALUES ($1) RETURNING id, created_at, updated_at`).
-					WithArgs(r.Name).
+				mock.ExpectQuery(`INSERT INTO "role" (name, require_2fa) VALUES ($1, $2) RETURNING id, created_at, updated_at`).
+					WithArgs(r.Name, r.Require2FA).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
 
@@ -213,7 +309,7 @@ func TestUpdateRole(t *testing.T) {
 				require.Equal(t, expectedCreatedAt, cp.CreatedAt)
 				require.Equal(t, expectedUpdatedAt, cp.UpdatedAt)
 
-				mock.ExpectExec(`UPDATE "role" SET name=?, updated_at=? WHERE id=?`).
+				mock.ExpectExec(`UPDATE "role" SET name=?, require_2fa=?, updated_at=? WHERE id=?`).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				up, err := repo.UpdateRole(context.Background(), nr)
@@ -228,7 +324,7 @@ func TestUpdateRole(t *testing.T) {
 		{
 			name: "failed updating role",
 			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`UPDATE "role" SET name=?, updated_at=? WHERE id=?`).
+				mock.ExpectExec(`UPDATE "role" SET name=?, require_2fa=?, updated_at=? WHERE id=?`).
 					WillReturnError(fmt.Errorf("error updating role"))
 
 				_, err := repo.UpdateRole(context.Background(), r)
@@ -260,7 +356,7 @@ func TestDeleteRole(t *testing.T) {
 		{
 			name: "success",
 			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`DELETE FROM "role" WHERE id=$1`).
+				mock.ExpectExec(`UPDATE "role" SET deleted_at=now(), updated_at=now() WHERE id=$1 AND deleted_at IS NULL`).
 					WithArgs(expectedID).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -271,10 +367,25 @@ func TestDeleteRole(t *testing.T) {
 				require.NoError(t, err)
 			},
 		},
+		{
+			name: "role already deleted",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "role" SET deleted_at=now(), updated_at=now() WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				err := repo.DeleteRole(context.Background(), expectedID)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+				require.ErrorIs(t, err, apperror.ErrNotFound)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
 		{
 			name: "failed deleting role",
 			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`DELETE FROM "role" WHERE id=$1`).
+				mock.ExpectExec(`UPDATE "role" SET deleted_at=now(), updated_at=now() WHERE id=$1 AND deleted_at IS NULL`).
 					WithArgs(expectedID).
 					WillReturnError(fmt.Errorf("error deleting role"))
 
@@ -296,3 +407,151 @@ func TestDeleteRole(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreRole(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *RoleRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "name", "require_2fa"}).
+					AddRow(expectedID, time.Now(), time.Now(), nil, "Auditor", false)
+
+				mock.ExpectQuery(`UPDATE "role" SET deleted_at=NULL, updated_at=now() WHERE id=$1 AND deleted_at IS NOT NULL RETURNING *`).
+					WithArgs(expectedID).
+					WillReturnRows(rows)
+
+				record, err := repo.RestoreRole(context.Background(), expectedID)
+				require.NoError(t, err)
+				require.Nil(t, record.DeletedAt)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "role not deleted",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE "role" SET deleted_at=NULL, updated_at=now() WHERE id=$1 AND deleted_at IS NOT NULL RETURNING *`).
+					WithArgs(expectedID).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.RestoreRole(context.Background(), expectedID)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+				require.ErrorIs(t, err, apperror.ErrNotFound)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewRoleRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestForceDeleteRole(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *RoleRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "role" WHERE id=$1`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				err := repo.ForceDeleteRole(context.Background(), expectedID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "role not found",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "role" WHERE id=$1`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				err := repo.ForceDeleteRole(context.Background(), expectedID)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+				require.ErrorIs(t, err, apperror.ErrNotFound)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewRoleRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestIsRoleInUse(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *RoleRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS (SELECT 1 FROM "user" WHERE role_id=$1 AND deleted_at IS NULL)`).
+					WithArgs(expectedID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+				inUse, err := repo.IsRoleInUse(context.Background(), expectedID)
+				require.NoError(t, err)
+				require.True(t, inUse)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed checking role users",
+			test: func(t *testing.T, repo *RoleRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS (SELECT 1 FROM "user" WHERE role_id=$1 AND deleted_at IS NULL)`).
+					WithArgs(expectedID).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.IsRoleInUse(context.Background(), expectedID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewRoleRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
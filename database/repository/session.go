@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"gofi/database/entity"
 	"time"
@@ -28,12 +29,12 @@ func (repo *SessionRepository) CreateSession(ctx context.Context, s *entity.Sess
 	)
 
 	const query_insert = `
-		INSERT INTO "session" (user_id, token, expired_at) 
-		VALUES ($1, $2, $3)
+		INSERT INTO "session" (user_id, token, expired_at, refresh_token_hash, refresh_expired_at, family_id, ip_address, user_agent, device)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := repo.db.QueryRowContext(ctx, query_insert, s.UserID, s.Token, s.ExpiredAt).
+	err := repo.db.QueryRowContext(ctx, query_insert, s.UserID, s.Token, s.ExpiredAt, s.RefreshTokenHash, s.RefreshExpiredAt, s.FamilyID, s.IPAddress, s.UserAgent, s.Device).
 		Scan(&lastInsertID, &createdAt, &updatedAt)
 
 	if err != nil {
@@ -57,27 +58,221 @@ func (repo *SessionRepository) GetSession(ctx context.Context, id uuid.UUID, tok
 
 	err := repo.db.GetContext(ctx, &s, query_find_one, id, token)
 	if err != nil {
-		return nil, fmt.Errorf("error getting session: %v", err)
+		return nil, fmt.Errorf("error getting session: %w", notFound("session", err))
 	}
 
 	return &s, nil
 }
 
-func (repo *SessionRepository) ListSessions(ctx context.Context) ([]entity.Session, error) {
+func (repo *SessionRepository) GetSessionByToken(ctx context.Context, token string) (*entity.Session, error) {
+	var s entity.Session
+
+	const query_find_by_token = `
+		SELECT * FROM "session"
+		WHERE token=$1
+	`
+
+	err := repo.db.GetContext(ctx, &s, query_find_by_token, token)
+	if err != nil {
+		return nil, fmt.Errorf("error getting session: %w", err)
+	}
+
+	return &s, nil
+}
+
+func (repo *SessionRepository) GetSessionByRefreshTokenHash(ctx context.Context, hash string) (*entity.Session, error) {
+	var s entity.Session
+
+	const query_find_by_refresh = `
+		SELECT * FROM "session"
+		WHERE refresh_token_hash=$1
+	`
+
+	err := repo.db.GetContext(ctx, &s, query_find_by_refresh, hash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting session: %w", err)
+	}
+
+	return &s, nil
+}
+
+// RotateSession revokes the current session and inserts next in its place in
+// a single transaction. It returns sql.ErrNoRows when the current session was
+// already revoked, e.g. by a concurrent rotation with the same refresh token.
+func (repo *SessionRepository) RotateSession(ctx context.Context, currentID uuid.UUID, next *entity.Session) (*entity.Session, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error rotating session: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_revoke = `
+		UPDATE "session" SET revoked_at=$2, updated_at=$2
+		WHERE id=$1 AND revoked_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query_revoke, currentID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error revoking session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error revoking session: %w", err)
+	}
+
+	if affected == 0 {
+		return nil, fmt.Errorf("error revoking session: %w", sql.ErrNoRows)
+	}
+
+	const query_insert = `
+		INSERT INTO "session" (user_id, token, expired_at, refresh_token_hash, refresh_expired_at, family_id, ip_address, user_agent, device)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query_insert, next.UserID, next.Token, next.ExpiredAt, next.RefreshTokenHash, next.RefreshExpiredAt, next.FamilyID, next.IPAddress, next.UserAgent, next.Device).
+		Scan(&next.ID, &next.CreatedAt, &next.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error rotating session: %w", err)
+	}
+
+	return next, nil
+}
+
+// RevokeSessionFamily revokes every session of the family that is still active.
+func (repo *SessionRepository) RevokeSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	const query_revoke = `
+		UPDATE "session" SET revoked_at=$2, updated_at=$2
+		WHERE family_id=$1 AND revoked_at IS NULL
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_revoke, familyID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("error revoking session family: %v", err)
+	}
+
+	return nil
+}
+
+// sessionColumns are the session fields that can be sorted and filtered on.
+var sessionColumns = columns{
+	"id":                 {expr: "id", filter: filterUUID},
+	"created_at":         {expr: "created_at"},
+	"updated_at":         {expr: "updated_at"},
+	"expired_at":         {expr: "expired_at"},
+	"refresh_expired_at": {expr: "refresh_expired_at"},
+	"revoked_at":         {expr: "revoked_at"},
+	"user_id":            {expr: "user_id", filter: filterUUID},
+	"family_id":          {expr: "family_id", filter: filterUUID},
+	"ip_address":         {expr: "ip_address", filter: filterExact},
+	"device":             {expr: "device", filter: filterSearch},
+}
+
+// ListSessions lists a page of the sessions. Offset pages count the sessions
+// matching, keyset pages link to the pages around them.
+func (repo *SessionRepository) ListSessions(ctx context.Context, opts entity.QueryOptions) ([]entity.Session, entity.Page, error) {
+	var sessions []entity.Session
+	var page entity.Page
+
+	query_find_all, query_count, args, err := listQuery(`"session"`, nil, nil, opts, sessionColumns, "-created_at")
+	if err != nil {
+		return nil, page, fmt.Errorf("error listing session: %w", err)
+	}
+
+	if !opts.Keyset {
+		err = repo.db.GetContext(ctx, &page.Total, query_count, args...)
+		if err != nil {
+			return nil, page, fmt.Errorf("error counting session: %v", err)
+		}
+	}
+
+	err = repo.db.SelectContext(ctx, &sessions, query_find_all, args...)
+	if err != nil {
+		return nil, page, fmt.Errorf("error listing session: %v", err)
+	}
+
+	if opts.Keyset {
+		sessions, page = keysetPage(sessions, opts, func(s entity.Session) entity.Cursor {
+			return entity.Cursor{CreatedAt: s.CreatedAt, ID: s.ID}
+		})
+	}
+
+	return sessions, page, nil
+}
+
+// ListActiveSessionsByUser lists the sessions of the user that are neither
+// revoked nor past their refresh expiry, newest first.
+func (repo *SessionRepository) ListActiveSessionsByUser(ctx context.Context, userID uuid.UUID) ([]entity.Session, error) {
 	var sessions []entity.Session
 
-	const query_find_all = `
+	const query_find_by_user = `
 		SELECT * FROM "session"
+		WHERE user_id=$1 AND revoked_at IS NULL AND COALESCE(refresh_expired_at, expired_at) > $2
+		ORDER BY created_at DESC
 	`
 
-	err := repo.db.SelectContext(ctx, &sessions, query_find_all)
+	err := repo.db.SelectContext(ctx, &sessions, query_find_by_user, userID, time.Now().UTC())
 	if err != nil {
-		return nil, fmt.Errorf("error listing session: %v", err)
+		return nil, fmt.Errorf("error listing session: %w", err)
 	}
 
 	return sessions, nil
 }
 
+// RevokeUserSession revokes the family of the session, which must belong to
+// the user. It returns sql.ErrNoRows when there is no such active session.
+func (repo *SessionRepository) RevokeUserSession(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	const query_revoke = `
+		UPDATE "session" SET revoked_at=$3, updated_at=$3
+		WHERE revoked_at IS NULL AND family_id=(
+			SELECT family_id FROM "session" WHERE id=$1 AND user_id=$2
+		)
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_revoke, id, userID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error revoking session: %w", notFound("session", sql.ErrNoRows))
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions revokes every active session of the user outside the
+// given family, i.e. everywhere but the current device.
+func (repo *SessionRepository) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (int64, error) {
+	const query_revoke = `
+		UPDATE "session" SET revoked_at=$3, updated_at=$3
+		WHERE user_id=$1 AND family_id<>$2 AND revoked_at IS NULL
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_revoke, userID, familyID, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	return affected, nil
+}
+
 func (repo *SessionRepository) UpdateSession(ctx context.Context, s *entity.Session) (*entity.Session, error) {
 	const query_update = `
 		UPDATE "session" SET user_id=:user_id, token=:token, expired_at=:expired_at, updated_at=:updated_at 
@@ -105,3 +300,25 @@ func (repo *SessionRepository) DeleteSession(ctx context.Context, id uuid.UUID)
 
 	return nil
 }
+
+// PurgeExpiredSessions deletes the sessions whose refresh token expired before
+// the given time. Revoked sessions are kept until then, they are needed to
+// detect the reuse of a rotated refresh token.
+func (repo *SessionRepository) PurgeExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	const query_purge = `
+		DELETE FROM "session"
+		WHERE COALESCE(refresh_expired_at, expired_at) < $1
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_purge, before)
+	if err != nil {
+		return 0, fmt.Errorf("error purging sessions: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error purging sessions: %w", err)
+	}
+
+	return affected, nil
+}
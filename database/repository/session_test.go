@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"gofi/database/entity"
 	"testing"
@@ -33,8 +34,8 @@ func TestCreateSession(t *testing.T) {
 				expectedCreatedAt := time.Now()
 				expectedUpdatedAt := time.Now()
 
-				mock.ExpectQuery(`INSERT INTO "session" (user_id, token, expired_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
-					WithArgs(s.UserID, s.Token, s.ExpiredAt).
+				mock.ExpectQuery(`INSERT INTO "session" (user_id, token, expired_at, refresh_token_hash, refresh_expired_at, family_id, ip_address, user_agent, device) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at`).
+					WithArgs(s.UserID, s.Token, s.ExpiredAt, s.RefreshTokenHash, s.RefreshExpiredAt, s.FamilyID, s.IPAddress, s.UserAgent, s.Device).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
 
@@ -52,8 +53,8 @@ func TestCreateSession(t *testing.T) {
 		{
 			name: "failed inserting session",
 			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`INSERT INTO "session" (user_id, token, expired_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
-					WithArgs(s.UserID, s.Token, s.ExpiredAt).
+				mock.ExpectQuery(`INSERT INTO "session" (user_id, token, expired_at, refresh_token_hash, refresh_expired_at, family_id, ip_address, user_agent, device) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at`).
+					WithArgs(s.UserID, s.Token, s.ExpiredAt, s.RefreshTokenHash, s.RefreshExpiredAt, s.FamilyID, s.IPAddress, s.UserAgent, s.Device).
 					WillReturnError(fmt.Errorf("error inserting session"))
 
 				_, err := repo.CreateSession(context.Background(), s)
@@ -132,6 +133,64 @@ func TestGetSession(t *testing.T) {
 	}
 }
 
+func TestGetSessionByToken(t *testing.T) {
+	s := &entity.Session{
+		UserID:    uuid.New(),
+		Token:     "test token",
+		ExpiredAt: time.Now(),
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *SessionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "token", "expired_at"}).
+					AddRow(expectedID, s.CreatedAt, s.UpdatedAt, s.UserID, s.Token, s.ExpiredAt)
+
+				mock.ExpectQuery(`SELECT * FROM "session" WHERE token=$1`).
+					WithArgs(s.Token).
+					WillReturnRows(rows)
+
+				record, err := repo.GetSessionByToken(context.Background(), s.Token)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, s.UserID, record.UserID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "session not found",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "session" WHERE token=$1`).
+					WithArgs(s.Token).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetSessionByToken(context.Background(), s.Token)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewSessionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
 func TestListSessions(t *testing.T) {
 	s := &entity.Session{
 		UserID:    uuid.New(),
@@ -151,22 +210,85 @@ func TestListSessions(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "token", "expired_at"}).
 					AddRow(expectedID, s.CreatedAt, s.UpdatedAt, s.UserID, s.Token, s.ExpiredAt)
 
-				mock.ExpectQuery(`SELECT * FROM "session"`).WillReturnRows(rows)
+				mock.ExpectQuery(`SELECT count(*) FROM "session" WHERE user_id=$1`).WithArgs(s.UserID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT * FROM "session" WHERE user_id=$1 ORDER BY created_at DESC, id ASC LIMIT 20 OFFSET 0`).WithArgs(s.UserID).WillReturnRows(rows)
 
-				records, err := repo.ListSessions(context.Background())
+				opts := entity.QueryOptions{Page: 1, PageSize: 20, Filters: map[string]string{"user_id": s.UserID.String()}}
+				records, page, err := repo.ListSessions(context.Background(), opts)
 				require.NoError(t, err)
 				require.Len(t, records, 1)
+				require.Equal(t, 1, page.Total)
 
 				err = mock.ExpectationsWereMet()
 				require.NoError(t, err)
 			},
 		},
 		{
-			name: "failed querying session",
+			name: "first keyset page",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				newer, older := time.Now(), time.Now().Add(-time.Hour)
+
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "token", "expired_at"}).
+					AddRow(expectedID, newer, newer, s.UserID, s.Token, s.ExpiredAt).
+					AddRow(uuid.New(), older, older, s.UserID, s.Token, s.ExpiredAt)
+
+				mock.ExpectQuery(`SELECT * FROM "session" ORDER BY created_at DESC, id DESC LIMIT 2`).WillReturnRows(rows)
+
+				records, page, err := repo.ListSessions(context.Background(), entity.QueryOptions{PageSize: 1, Keyset: true})
+				require.NoError(t, err)
+				require.Len(t, records, 1)
+				require.Nil(t, page.Prev)
+				require.Equal(t, &entity.Cursor{CreatedAt: newer, ID: expectedID}, page.Next)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "previous keyset page",
 			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT * FROM "session"`).WillReturnError(fmt.Errorf("error querying session"))
+				cursor := &entity.Cursor{CreatedAt: time.Now().Add(-time.Hour), ID: uuid.New(), Prev: true}
+				newer, newest := time.Now(), time.Now().Add(time.Hour)
+				newestID := uuid.New()
 
-				_, err := repo.ListSessions(context.Background())
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "token", "expired_at"}).
+					AddRow(expectedID, newer, newer, s.UserID, s.Token, s.ExpiredAt).
+					AddRow(newestID, newest, newest, s.UserID, s.Token, s.ExpiredAt)
+
+				mock.ExpectQuery(`SELECT * FROM "session" WHERE created_at>=$1 AND (created_at, id) > ($1, $2) ORDER BY created_at ASC, id ASC LIMIT 3`).
+					WithArgs(cursor.CreatedAt, cursor.ID).
+					WillReturnRows(rows)
+
+				records, page, err := repo.ListSessions(context.Background(), entity.QueryOptions{PageSize: 2, Keyset: true, Cursor: cursor})
+				require.NoError(t, err)
+				require.Len(t, records, 2)
+				require.Equal(t, newestID, records[0].ID)
+				require.Nil(t, page.Prev)
+				require.Equal(t, &entity.Cursor{CreatedAt: newer, ID: expectedID}, page.Next)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "invalid filter value",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				opts := entity.QueryOptions{Page: 1, PageSize: 20, Filters: map[string]string{"user_id": "me"}}
+
+				_, _, err := repo.ListSessions(context.Background(), opts)
+				require.ErrorIs(t, err, ErrInvalidQuery)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed counting session",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count(*) FROM "session"`).WillReturnError(fmt.Errorf("error querying session"))
+
+				_, _, err := repo.ListSessions(context.Background(), entity.QueryOptions{Page: 1, PageSize: 20})
 				require.Error(t, err)
 
 				err = mock.ExpectationsWereMet()
@@ -212,8 +334,8 @@ func TestUpdateSession(t *testing.T) {
 				expectedCreatedAt := time.Now()
 				expectedUpdatedAt := time.Now()
 
-				mock.ExpectQuery(`INSERT INTO "session" (user_id, token, expired_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
-					WithArgs(s.UserID, s.Token, s.ExpiredAt).
+				mock.ExpectQuery(`INSERT INTO "session" (user_id, token, expired_at, refresh_token_hash, refresh_expired_at, family_id, ip_address, user_agent, device) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at`).
+					WithArgs(s.UserID, s.Token, s.ExpiredAt, s.RefreshTokenHash, s.RefreshExpiredAt, s.FamilyID, s.IPAddress, s.UserAgent, s.Device).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
 
@@ -308,3 +430,372 @@ func TestDeleteSession(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSessionByRefreshTokenHash(t *testing.T) {
+	expectedID := uuid.New()
+	familyID := uuid.New()
+	hash := "refresh-token-hash"
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *SessionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "token", "expired_at", "refresh_token_hash", "refresh_expired_at", "family_id", "revoked_at"}).
+					AddRow(expectedID, time.Now(), time.Now(), uuid.New(), "test token", time.Now(), hash, time.Now(), familyID, nil)
+
+				mock.ExpectQuery(`SELECT * FROM "session" WHERE refresh_token_hash=$1`).
+					WithArgs(hash).
+					WillReturnRows(rows)
+
+				record, err := repo.GetSessionByRefreshTokenHash(context.Background(), hash)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, familyID, record.FamilyID)
+				require.Nil(t, record.RevokedAt)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "session not found",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "session" WHERE refresh_token_hash=$1`).
+					WithArgs(hash).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetSessionByRefreshTokenHash(context.Background(), hash)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewSessionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestRotateSession(t *testing.T) {
+	currentID := uuid.New()
+	hash := "next-refresh-token-hash"
+	refreshExpiredAt := time.Now().Add(time.Hour)
+
+	next := &entity.Session{
+		UserID:           uuid.New(),
+		Token:            "next token",
+		ExpiredAt:        time.Now(),
+		RefreshTokenHash: &hash,
+		RefreshExpiredAt: &refreshExpiredAt,
+		FamilyID:         uuid.New(),
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *SessionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=$2, updated_at=$2 WHERE id=$1 AND revoked_at IS NULL`).
+					WithArgs(currentID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "session" (user_id, token, expired_at, refresh_token_hash, refresh_expired_at, family_id, ip_address, user_agent, device) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at`).
+					WithArgs(next.UserID, next.Token, next.ExpiredAt, next.RefreshTokenHash, next.RefreshExpiredAt, next.FamilyID, next.IPAddress, next.UserAgent, next.Device).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, time.Now(), time.Now()))
+				mock.ExpectCommit()
+
+				record, err := repo.RotateSession(context.Background(), currentID, next)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "session already revoked",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=$2, updated_at=$2 WHERE id=$1 AND revoked_at IS NULL`).
+					WithArgs(currentID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+
+				_, err := repo.RotateSession(context.Background(), currentID, next)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewSessionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestRevokeSessionFamily(t *testing.T) {
+	familyID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *SessionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=$2, updated_at=$2 WHERE family_id=$1 AND revoked_at IS NULL`).
+					WithArgs(familyID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				err := repo.RevokeSessionFamily(context.Background(), familyID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed revoking session family",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=$2, updated_at=$2 WHERE family_id=$1 AND revoked_at IS NULL`).
+					WithArgs(familyID, sqlmock.AnyArg()).
+					WillReturnError(fmt.Errorf("error revoking session family"))
+
+				err := repo.RevokeSessionFamily(context.Background(), familyID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewSessionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestListActiveSessionsByUser(t *testing.T) {
+	userID := uuid.New()
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *SessionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "token", "expired_at", "ip_address", "user_agent", "device"}).
+					AddRow(expectedID, time.Now(), time.Now(), userID, "test token", time.Now(), "203.0.113.7", "Mozilla/5.0", "Firefox on Linux")
+
+				mock.ExpectQuery(`SELECT * FROM "session" WHERE user_id=$1 AND revoked_at IS NULL AND COALESCE(refresh_expired_at, expired_at) > $2 ORDER BY created_at DESC`).
+					WithArgs(userID, sqlmock.AnyArg()).
+					WillReturnRows(rows)
+
+				records, err := repo.ListActiveSessionsByUser(context.Background(), userID)
+				require.NoError(t, err)
+				require.Len(t, records, 1)
+				require.Equal(t, "Firefox on Linux", *records[0].Device)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed querying session",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "session" WHERE user_id=$1 AND revoked_at IS NULL AND COALESCE(refresh_expired_at, expired_at) > $2 ORDER BY created_at DESC`).
+					WithArgs(userID, sqlmock.AnyArg()).
+					WillReturnError(fmt.Errorf("error querying session"))
+
+				_, err := repo.ListActiveSessionsByUser(context.Background(), userID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewSessionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestRevokeUserSession(t *testing.T) {
+	id := uuid.New()
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *SessionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=$3, updated_at=$3 WHERE revoked_at IS NULL AND family_id=( SELECT family_id FROM "session" WHERE id=$1 AND user_id=$2 )`).
+					WithArgs(id, userID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				err := repo.RevokeUserSession(context.Background(), id, userID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "session of another user",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=$3, updated_at=$3 WHERE revoked_at IS NULL AND family_id=( SELECT family_id FROM "session" WHERE id=$1 AND user_id=$2 )`).
+					WithArgs(id, userID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				err := repo.RevokeUserSession(context.Background(), id, userID)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewSessionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	userID := uuid.New()
+	familyID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *SessionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=$3, updated_at=$3 WHERE user_id=$1 AND family_id<>$2 AND revoked_at IS NULL`).
+					WithArgs(userID, familyID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+
+				revoked, err := repo.RevokeOtherSessions(context.Background(), userID, familyID)
+				require.NoError(t, err)
+				require.Equal(t, int64(3), revoked)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed revoking sessions",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=$3, updated_at=$3 WHERE user_id=$1 AND family_id<>$2 AND revoked_at IS NULL`).
+					WithArgs(userID, familyID, sqlmock.AnyArg()).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.RevokeOtherSessions(context.Background(), userID, familyID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewSessionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestPurgeExpiredSessions(t *testing.T) {
+	before := time.Now().UTC()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *SessionRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "session" WHERE COALESCE(refresh_expired_at, expired_at) < $1`).
+					WithArgs(before).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				purged, err := repo.PurgeExpiredSessions(context.Background(), before)
+				require.NoError(t, err)
+				require.Equal(t, int64(2), purged)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed purging sessions",
+			test: func(t *testing.T, repo *SessionRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "session" WHERE COALESCE(refresh_expired_at, expired_at) < $1`).
+					WithArgs(before).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.PurgeExpiredSessions(context.Background(), before)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewSessionRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
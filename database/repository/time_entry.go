@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type TimeEntryRepository struct {
+	db *sqlx.DB
+}
+
+func NewTimeEntryRepository(db *sqlx.DB) *TimeEntryRepository {
+	return &TimeEntryRepository{
+		db: db,
+	}
+}
+
+func (repo *TimeEntryRepository) CreateTimeEntry(ctx context.Context, r *entity.TimeEntry) (*entity.TimeEntry, error) {
+	var (
+		lastInsertID uuid.UUID
+		createdAt    time.Time
+		updatedAt    time.Time
+	)
+
+	const query_insert = `
+		INSERT INTO "time_entry" (user_id, project_id, started_at, ended_at, duration, description, is_billable)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := repo.db.QueryRowContext(ctx, query_insert, r.UserID, r.ProjectID, r.StartedAt, r.EndedAt, r.Duration, r.Description, r.IsBillable).
+		Scan(&lastInsertID, &createdAt, &updatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting time entry: %w", err)
+	}
+
+	r.ID = lastInsertID
+	r.CreatedAt = createdAt
+	r.UpdatedAt = updatedAt
+
+	return r, nil
+}
+
+func (repo *TimeEntryRepository) GetTimeEntry(ctx context.Context, id uuid.UUID) (*entity.TimeEntry, error) {
+	var r entity.TimeEntry
+
+	const query_find_one = `
+		SELECT * FROM "time_entry"
+		WHERE id=$1 AND deleted_at IS NULL
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_one, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting time entry: %w", notFound("time entry", err))
+	}
+
+	return &r, nil
+}
+
+// timeEntryColumns are the time entry fields that can be sorted and filtered on.
+var timeEntryColumns = columns{
+	"id":          {expr: "id", filter: filterUUID},
+	"created_at":  {expr: "created_at"},
+	"updated_at":  {expr: "updated_at"},
+	"started_at":  {expr: "started_at"},
+	"ended_at":    {expr: "ended_at"},
+	"duration":    {expr: "duration"},
+	"user_id":     {expr: "user_id", filter: filterUUID},
+	"project_id":  {expr: "project_id", filter: filterUUID},
+	"description": {expr: "description", filter: filterSearch},
+	"is_billable": {expr: "is_billable", filter: filterBool},
+}
+
+// ListTimeEntriesByUser lists a page of the user's time entries along with
+// the number of entries matching.
+func (repo *TimeEntryRepository) ListTimeEntriesByUser(ctx context.Context, userID uuid.UUID, opts entity.QueryOptions) ([]entity.TimeEntry, int, error) {
+	var entries []entity.TimeEntry
+	var total int
+
+	query_find_by_user, query_count, args, err := listQuery(`"time_entry"`, []string{"user_id=$1", "deleted_at IS NULL"}, []interface{}{userID}, opts, timeEntryColumns, "-started_at")
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing time entries: %w", err)
+	}
+
+	err = repo.db.GetContext(ctx, &total, query_count, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting time entries: %v", err)
+	}
+
+	err = repo.db.SelectContext(ctx, &entries, query_find_by_user, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing time entries: %v", err)
+	}
+
+	return entries, total, nil
+}
+
+// CountOverlappingTimeEntries counts the user's entries sharing any instant
+// with [startedAt, endedAt), ignoring the entry identified by excludeID. A
+// running entry is treated as open-ended.
+func (repo *TimeEntryRepository) CountOverlappingTimeEntries(ctx context.Context, userID uuid.UUID, startedAt time.Time, endedAt time.Time, excludeID uuid.UUID) (int, error) {
+	var total int
+
+	const query_count = `
+		SELECT count(*) FROM "time_entry"
+		WHERE user_id=$1 AND id<>$2 AND deleted_at IS NULL AND started_at<$4 AND COALESCE(ended_at, 'infinity')>$3
+	`
+
+	err := repo.db.GetContext(ctx, &total, query_count, userID, excludeID, startedAt, endedAt)
+	if err != nil {
+		return 0, fmt.Errorf("error counting overlapping time entries: %v", err)
+	}
+
+	return total, nil
+}
+
+func (repo *TimeEntryRepository) GetRunningTimeEntry(ctx context.Context, userID uuid.UUID) (*entity.TimeEntry, error) {
+	var r entity.TimeEntry
+
+	const query_find_running = `
+		SELECT * FROM "time_entry"
+		WHERE user_id=$1 AND ended_at IS NULL AND deleted_at IS NULL
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_running, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting running time entry: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *TimeEntryRepository) GetLatestTimeEntry(ctx context.Context, userID uuid.UUID) (*entity.TimeEntry, error) {
+	var r entity.TimeEntry
+
+	const query_find_latest = `
+		SELECT * FROM "time_entry"
+		WHERE user_id=$1 AND ended_at IS NOT NULL AND deleted_at IS NULL
+		ORDER BY ended_at DESC
+		LIMIT 1
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_latest, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting latest time entry: %w", err)
+	}
+
+	return &r, nil
+}
+
+// StartTimeEntry stops the user's running entry, if any, at r.StartedAt and
+// inserts r as the new running entry in a single transaction.
+func (repo *TimeEntryRepository) StartTimeEntry(ctx context.Context, r *entity.TimeEntry) (*entity.TimeEntry, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting time entry: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_stop = `
+		UPDATE "time_entry" SET ended_at=$2, duration=EXTRACT(EPOCH FROM ($2::timestamp - started_at))::bigint, updated_at=$2
+		WHERE user_id=$1 AND ended_at IS NULL AND deleted_at IS NULL
+	`
+
+	_, err = tx.ExecContext(ctx, query_stop, r.UserID, r.StartedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error stopping running time entry: %w", err)
+	}
+
+	const query_insert = `
+		INSERT INTO "time_entry" (user_id, project_id, started_at, description, is_billable)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query_insert, r.UserID, r.ProjectID, r.StartedAt, r.Description, r.IsBillable).
+		Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting time entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error starting time entry: %w", err)
+	}
+
+	return r, nil
+}
+
+func (repo *TimeEntryRepository) StopRunningTimeEntry(ctx context.Context, userID uuid.UUID, endedAt time.Time) (*entity.TimeEntry, error) {
+	var r entity.TimeEntry
+
+	const query_stop = `
+		UPDATE "time_entry" SET ended_at=$2, duration=EXTRACT(EPOCH FROM ($2::timestamp - started_at))::bigint, updated_at=$2
+		WHERE user_id=$1 AND ended_at IS NULL AND deleted_at IS NULL
+		RETURNING *
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_stop, userID, endedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error stopping running time entry: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *TimeEntryRepository) UpdateTimeEntry(ctx context.Context, r *entity.TimeEntry) (*entity.TimeEntry, error) {
+	const query_update = `
+		UPDATE "time_entry" SET user_id=:user_id, project_id=:project_id, started_at=:started_at, ended_at=:ended_at,
+		duration=:duration, description=:description, is_billable=:is_billable, updated_at=:updated_at
+		WHERE id=:id
+	`
+
+	_, err := repo.db.NamedExecContext(ctx, query_update, r)
+	if err != nil {
+		return nil, fmt.Errorf("error updating time entry: %w", err)
+	}
+
+	return r, nil
+}
+
+// DeleteTimeEntry soft deletes the time entry.
+func (repo *TimeEntryRepository) DeleteTimeEntry(ctx context.Context, id uuid.UUID) error {
+	const query_delete = `
+		UPDATE "time_entry" SET deleted_at=$2, updated_at=$2
+		WHERE id=$1 AND deleted_at IS NULL
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_delete, id, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("error deleting time entry: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,426 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTimeEntry(t *testing.T) {
+	startedAt := time.Now().Add(-time.Hour)
+	endedAt := time.Now()
+
+	te := &entity.TimeEntry{
+		UserID:      uuid.New(),
+		ProjectID:   uuid.New(),
+		StartedAt:   startedAt,
+		EndedAt:     &endedAt,
+		Duration:    3600,
+		Description: "Test Description",
+		IsBillable:  true,
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimeEntryRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				// Mock the expected query and result
+				expectedCreatedAt := time.Now()
+				expectedUpdatedAt := time.Now()
+
+				mock.ExpectQuery(`INSERT INTO "time_entry" (user_id, project_id, started_at, ended_at, duration, description, is_billable) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, updated_at`).
+					WithArgs(te.UserID, te.ProjectID, te.StartedAt, te.EndedAt, te.Duration, te.Description, te.IsBillable).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
+
+				record, err := repo.CreateTimeEntry(context.Background(), te)
+				require.NoError(t, err)
+				require.NotNil(t, record)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, expectedCreatedAt, record.CreatedAt)
+				require.Equal(t, expectedUpdatedAt, record.UpdatedAt)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting time entry",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "time_entry" (user_id, project_id, started_at, ended_at, duration, description, is_billable) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, updated_at`).
+					WithArgs(te.UserID, te.ProjectID, te.StartedAt, te.EndedAt, te.Duration, te.Description, te.IsBillable).
+					WillReturnError(fmt.Errorf("error inserting time entry"))
+
+				_, err := repo.CreateTimeEntry(context.Background(), te)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimeEntryRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestGetTimeEntry(t *testing.T) {
+	endedAt := time.Now()
+
+	te := &entity.TimeEntry{
+		UserID:      uuid.New(),
+		ProjectID:   uuid.New(),
+		StartedAt:   time.Now().Add(-time.Hour),
+		EndedAt:     &endedAt,
+		Duration:    3600,
+		Description: "Test Description",
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimeEntryRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "user_id", "project_id", "started_at", "ended_at", "duration", "description", "is_billable"}).
+					AddRow(expectedID, te.CreatedAt, te.UpdatedAt, te.DeletedAt, te.UserID, te.ProjectID, te.StartedAt, te.EndedAt, te.Duration, te.Description, te.IsBillable)
+
+				mock.ExpectQuery(`SELECT * FROM "time_entry" WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnRows(rows)
+
+				record, err := repo.GetTimeEntry(context.Background(), expectedID)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, te.Duration, record.Duration)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed getting time entry",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "time_entry" WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnError(fmt.Errorf("error getting time entry"))
+
+				_, err := repo.GetTimeEntry(context.Background(), expectedID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimeEntryRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestCountOverlappingTimeEntries(t *testing.T) {
+	userID := uuid.New()
+	excludeID := uuid.New()
+	startedAt := time.Now().Add(-time.Hour)
+	endedAt := time.Now()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimeEntryRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count(*) FROM "time_entry" WHERE user_id=$1 AND id<>$2 AND deleted_at IS NULL AND started_at<$4 AND COALESCE(ended_at, 'infinity')>$3`).
+					WithArgs(userID, excludeID, startedAt, endedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+				total, err := repo.CountOverlappingTimeEntries(context.Background(), userID, startedAt, endedAt, excludeID)
+				require.NoError(t, err)
+				require.Equal(t, 2, total)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed counting time entries",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count(*) FROM "time_entry" WHERE user_id=$1 AND id<>$2 AND deleted_at IS NULL AND started_at<$4 AND COALESCE(ended_at, 'infinity')>$3`).
+					WithArgs(userID, excludeID, startedAt, endedAt).
+					WillReturnError(fmt.Errorf("error counting time entries"))
+
+				_, err := repo.CountOverlappingTimeEntries(context.Background(), userID, startedAt, endedAt, excludeID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimeEntryRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestUpdateTimeEntry(t *testing.T) {
+	endedAt := time.Now()
+
+	te := &entity.TimeEntry{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		ProjectID:   uuid.New(),
+		StartedAt:   time.Now().Add(-time.Hour),
+		EndedAt:     &endedAt,
+		Duration:    3600,
+		Description: "Another Description",
+	}
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimeEntryRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "time_entry" SET user_id=?, project_id=?, started_at=?, ended_at=?, duration=?, description=?, is_billable=?, updated_at=? WHERE id=?`).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				record, err := repo.UpdateTimeEntry(context.Background(), te)
+				require.NoError(t, err)
+				require.Equal(t, te.Description, record.Description)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed updating time entry",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "time_entry" SET user_id=?, project_id=?, started_at=?, ended_at=?, duration=?, description=?, is_billable=?, updated_at=? WHERE id=?`).
+					WillReturnError(fmt.Errorf("error updating time entry"))
+
+				_, err := repo.UpdateTimeEntry(context.Background(), te)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimeEntryRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestDeleteTimeEntry(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimeEntryRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "time_entry" SET deleted_at=$2, updated_at=$2 WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				err := repo.DeleteTimeEntry(context.Background(), expectedID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed deleting time entry",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "time_entry" SET deleted_at=$2, updated_at=$2 WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID, sqlmock.AnyArg()).
+					WillReturnError(fmt.Errorf("error deleting time entry"))
+
+				err := repo.DeleteTimeEntry(context.Background(), expectedID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimeEntryRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestStartTimeEntry(t *testing.T) {
+	te := &entity.TimeEntry{
+		UserID:      uuid.New(),
+		ProjectID:   uuid.New(),
+		StartedAt:   time.Now(),
+		Description: "Test Description",
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimeEntryRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				expectedCreatedAt := time.Now()
+				expectedUpdatedAt := time.Now()
+
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "time_entry" SET ended_at=$2, duration=EXTRACT(EPOCH FROM ($2::timestamp - started_at))::bigint, updated_at=$2 WHERE user_id=$1 AND ended_at IS NULL AND deleted_at IS NULL`).
+					WithArgs(te.UserID, te.StartedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "time_entry" (user_id, project_id, started_at, description, is_billable) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`).
+					WithArgs(te.UserID, te.ProjectID, te.StartedAt, te.Description, te.IsBillable).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
+				mock.ExpectCommit()
+
+				record, err := repo.StartTimeEntry(context.Background(), te)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Nil(t, record.EndedAt)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting time entry",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "time_entry" SET ended_at=$2, duration=EXTRACT(EPOCH FROM ($2::timestamp - started_at))::bigint, updated_at=$2 WHERE user_id=$1 AND ended_at IS NULL AND deleted_at IS NULL`).
+					WithArgs(te.UserID, te.StartedAt).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`INSERT INTO "time_entry" (user_id, project_id, started_at, description, is_billable) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`).
+					WithArgs(te.UserID, te.ProjectID, te.StartedAt, te.Description, te.IsBillable).
+					WillReturnError(fmt.Errorf("error inserting time entry"))
+				mock.ExpectRollback()
+
+				_, err := repo.StartTimeEntry(context.Background(), te)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimeEntryRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestStopRunningTimeEntry(t *testing.T) {
+	userID := uuid.New()
+	expectedID := uuid.New()
+	startedAt := time.Now().Add(-time.Hour)
+	endedAt := time.Now()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimeEntryRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "user_id", "project_id", "started_at", "ended_at", "duration", "description", "is_billable"}).
+					AddRow(expectedID, startedAt, endedAt, nil, userID, uuid.New(), startedAt, endedAt, 3600, "", false)
+
+				mock.ExpectQuery(`UPDATE "time_entry" SET ended_at=$2, duration=EXTRACT(EPOCH FROM ($2::timestamp - started_at))::bigint, updated_at=$2 WHERE user_id=$1 AND ended_at IS NULL AND deleted_at IS NULL RETURNING *`).
+					WithArgs(userID, endedAt).
+					WillReturnRows(rows)
+
+				record, err := repo.StopRunningTimeEntry(context.Background(), userID, endedAt)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.NotNil(t, record.EndedAt)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "no running time entry",
+			test: func(t *testing.T, repo *TimeEntryRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE "time_entry" SET ended_at=$2, duration=EXTRACT(EPOCH FROM ($2::timestamp - started_at))::bigint, updated_at=$2 WHERE user_id=$1 AND ended_at IS NULL AND deleted_at IS NULL RETURNING *`).
+					WithArgs(userID, endedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+				_, err := repo.StopRunningTimeEntry(context.Background(), userID, endedAt)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimeEntryRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
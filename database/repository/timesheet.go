@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type TimesheetRepository struct {
+	db *sqlx.DB
+}
+
+func NewTimesheetRepository(db *sqlx.DB) *TimesheetRepository {
+	return &TimesheetRepository{
+		db: db,
+	}
+}
+
+func (repo *TimesheetRepository) CreateTimesheet(ctx context.Context, r *entity.Timesheet) (*entity.Timesheet, error) {
+	var (
+		lastInsertID uuid.UUID
+		createdAt    time.Time
+		updatedAt    time.Time
+	)
+
+	const query_insert = `
+		INSERT INTO "timesheet" (user_id, year, week, status, submitted_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := repo.db.QueryRowContext(ctx, query_insert, r.UserID, r.Year, r.Week, r.Status, r.SubmittedAt).
+		Scan(&lastInsertID, &createdAt, &updatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting timesheet: %w", err)
+	}
+
+	r.ID = lastInsertID
+	r.CreatedAt = createdAt
+	r.UpdatedAt = updatedAt
+
+	return r, nil
+}
+
+func (repo *TimesheetRepository) GetTimesheet(ctx context.Context, id uuid.UUID) (*entity.Timesheet, error) {
+	var r entity.Timesheet
+
+	const query_find_one = `
+		SELECT * FROM "timesheet"
+		WHERE id=$1
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_one, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting timesheet: %w", notFound("timesheet", err))
+	}
+
+	return &r, nil
+}
+
+func (repo *TimesheetRepository) GetTimesheetByWeek(ctx context.Context, userID uuid.UUID, year int, week int) (*entity.Timesheet, error) {
+	var r entity.Timesheet
+
+	const query_find_week = `
+		SELECT * FROM "timesheet"
+		WHERE user_id=$1 AND year=$2 AND week=$3
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_week, userID, year, week)
+	if err != nil {
+		return nil, fmt.Errorf("error getting timesheet: %w", err)
+	}
+
+	return &r, nil
+}
+
+// timesheetColumns are the timesheet fields that can be sorted and filtered on.
+var timesheetColumns = columns{
+	"id":           {expr: "id", filter: filterUUID},
+	"created_at":   {expr: "created_at"},
+	"updated_at":   {expr: "updated_at"},
+	"submitted_at": {expr: "submitted_at"},
+	"reviewed_at":  {expr: "reviewed_at"},
+	"user_id":      {expr: "user_id", filter: filterUUID},
+	"reviewer_id":  {expr: "reviewer_id", filter: filterUUID},
+	"year":         {expr: "year", filter: filterInt},
+	"week":         {expr: "week", filter: filterInt},
+	"status":       {expr: "status", filter: filterExact},
+}
+
+// ListTimesheets lists a page of the timesheets along with the number of
+// timesheets matching.
+func (repo *TimesheetRepository) ListTimesheets(ctx context.Context, opts entity.QueryOptions) ([]entity.Timesheet, int, error) {
+	var timesheets []entity.Timesheet
+	var total int
+
+	query_find_all, query_count, args, err := listQuery(`"timesheet"`, nil, nil, opts, timesheetColumns, "-year,-week")
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing timesheets: %w", err)
+	}
+
+	err = repo.db.GetContext(ctx, &total, query_count, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting timesheets: %v", err)
+	}
+
+	err = repo.db.SelectContext(ctx, &timesheets, query_find_all, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing timesheets: %v", err)
+	}
+
+	return timesheets, total, nil
+}
+
+// ListTimesheetsByUser lists a page of the user's timesheets along with the
+// number of timesheets matching.
+func (repo *TimesheetRepository) ListTimesheetsByUser(ctx context.Context, userID uuid.UUID, opts entity.QueryOptions) ([]entity.Timesheet, int, error) {
+	var timesheets []entity.Timesheet
+	var total int
+
+	query_find_by_user, query_count, args, err := listQuery(`"timesheet"`, []string{"user_id=$1"}, []interface{}{userID}, opts, timesheetColumns, "-year,-week")
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing timesheets: %w", err)
+	}
+
+	err = repo.db.GetContext(ctx, &total, query_count, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting timesheets: %v", err)
+	}
+
+	err = repo.db.SelectContext(ctx, &timesheets, query_find_by_user, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing timesheets: %v", err)
+	}
+
+	return timesheets, total, nil
+}
+
+func (repo *TimesheetRepository) UpdateTimesheet(ctx context.Context, r *entity.Timesheet) (*entity.Timesheet, error) {
+	const query_update = `
+		UPDATE "timesheet" SET status=:status, submitted_at=:submitted_at, reviewed_at=:reviewed_at,
+		reviewer_id=:reviewer_id, comment=:comment, updated_at=:updated_at
+		WHERE id=:id
+	`
+
+	_, err := repo.db.NamedExecContext(ctx, query_update, r)
+	if err != nil {
+		return nil, fmt.Errorf("error updating timesheet: %v", err)
+	}
+
+	return r, nil
+}
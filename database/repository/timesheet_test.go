@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTimesheet(t *testing.T) {
+	submittedAt := time.Now()
+
+	ts := &entity.Timesheet{
+		UserID:      uuid.New(),
+		Year:        2024,
+		Week:        32,
+		Status:      "submitted",
+		SubmittedAt: &submittedAt,
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimesheetRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimesheetRepository, mock sqlmock.Sqlmock) {
+				// Mock the expected query and result
+				expectedCreatedAt := time.Now()
+				expectedUpdatedAt := time.Now()
+
+				mock.ExpectQuery(`INSERT INTO "timesheet" (user_id, year, week, status, submitted_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`).
+					WithArgs(ts.UserID, ts.Year, ts.Week, ts.Status, ts.SubmittedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, expectedCreatedAt, expectedUpdatedAt))
+
+				record, err := repo.CreateTimesheet(context.Background(), ts)
+				require.NoError(t, err)
+				require.NotNil(t, record)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, expectedCreatedAt, record.CreatedAt)
+				require.Equal(t, expectedUpdatedAt, record.UpdatedAt)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting timesheet",
+			test: func(t *testing.T, repo *TimesheetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "timesheet" (user_id, year, week, status, submitted_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`).
+					WithArgs(ts.UserID, ts.Year, ts.Week, ts.Status, ts.SubmittedAt).
+					WillReturnError(fmt.Errorf("error inserting timesheet"))
+
+				_, err := repo.CreateTimesheet(context.Background(), ts)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimesheetRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestGetTimesheetByWeek(t *testing.T) {
+	userID := uuid.New()
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimesheetRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimesheetRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "year", "week", "status", "submitted_at", "reviewed_at", "reviewer_id", "comment"}).
+					AddRow(expectedID, time.Now(), time.Now(), userID, 2024, 32, "approved", time.Now(), time.Now(), uuid.New(), nil)
+
+				mock.ExpectQuery(`SELECT * FROM "timesheet" WHERE user_id=$1 AND year=$2 AND week=$3`).
+					WithArgs(userID, 2024, 32).
+					WillReturnRows(rows)
+
+				record, err := repo.GetTimesheetByWeek(context.Background(), userID, 2024, 32)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, "approved", record.Status)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "timesheet not found",
+			test: func(t *testing.T, repo *TimesheetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "timesheet" WHERE user_id=$1 AND year=$2 AND week=$3`).
+					WithArgs(userID, 2024, 32).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetTimesheetByWeek(context.Background(), userID, 2024, 32)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimesheetRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestListTimesheetsByUser(t *testing.T) {
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimesheetRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimesheetRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "year", "week", "status", "submitted_at", "reviewed_at", "reviewer_id", "comment"}).
+					AddRow(uuid.New(), time.Now(), time.Now(), userID, 2024, 31, "approved", time.Now(), time.Now(), uuid.New(), nil).
+					AddRow(uuid.New(), time.Now(), time.Now(), userID, 2024, 32, "submitted", time.Now(), nil, nil, nil)
+
+				mock.ExpectQuery(`SELECT count(*) FROM "timesheet" WHERE user_id=$1 AND year=$2`).
+					WithArgs(userID, 2024).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(`SELECT * FROM "timesheet" WHERE user_id=$1 AND year=$2 ORDER BY year DESC, week DESC, id ASC LIMIT 20 OFFSET 0`).
+					WithArgs(userID, 2024).
+					WillReturnRows(rows)
+
+				opts := entity.QueryOptions{Page: 1, PageSize: 20, Filters: map[string]string{"year": "2024"}}
+				timesheets, total, err := repo.ListTimesheetsByUser(context.Background(), userID, opts)
+				require.NoError(t, err)
+				require.Len(t, timesheets, 2)
+				require.Equal(t, 2, total)
+				require.Equal(t, userID, timesheets[1].UserID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed listing timesheets",
+			test: func(t *testing.T, repo *TimesheetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count(*) FROM "timesheet" WHERE user_id=$1`).
+					WithArgs(userID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(`SELECT * FROM "timesheet" WHERE user_id=$1 ORDER BY year DESC, week DESC, id ASC LIMIT 20 OFFSET 0`).
+					WithArgs(userID).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, _, err := repo.ListTimesheetsByUser(context.Background(), userID, entity.QueryOptions{Page: 1, PageSize: 20})
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimesheetRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestUpdateTimesheet(t *testing.T) {
+	reviewedAt := time.Now()
+	reviewerID := uuid.New()
+	comment := "missing friday"
+
+	ts := &entity.Timesheet{
+		ID:         uuid.New(),
+		UserID:     uuid.New(),
+		Year:       2024,
+		Week:       32,
+		Status:     "rejected",
+		ReviewedAt: &reviewedAt,
+		ReviewerID: &reviewerID,
+		Comment:    &comment,
+	}
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TimesheetRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TimesheetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "timesheet" SET status=?, submitted_at=?, reviewed_at=?, reviewer_id=?, comment=?, updated_at=? WHERE id=?`).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				record, err := repo.UpdateTimesheet(context.Background(), ts)
+				require.NoError(t, err)
+				require.Equal(t, "rejected", record.Status)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed updating timesheet",
+			test: func(t *testing.T, repo *TimesheetRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "timesheet" SET status=?, submitted_at=?, reviewed_at=?, reviewer_id=?, comment=?, updated_at=? WHERE id=?`).
+					WillReturnError(fmt.Errorf("error updating timesheet"))
+
+				_, err := repo.UpdateTimesheet(context.Background(), ts)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTimesheetRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
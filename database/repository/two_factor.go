@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type TwoFactorRepository struct {
+	db *sqlx.DB
+}
+
+func NewTwoFactorRepository(db *sqlx.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		db: db,
+	}
+}
+
+func (repo *TwoFactorRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*entity.UserTwoFactor, error) {
+	var r entity.UserTwoFactor
+
+	const query_find_one = `
+		SELECT * FROM "user_two_factor"
+		WHERE user_id=$1
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_one, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting two factor: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *TwoFactorRepository) IsTwoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var exists bool
+
+	const query_is_enabled = `
+		SELECT EXISTS (SELECT 1 FROM "user_two_factor" WHERE user_id=$1 AND confirmed_at IS NOT NULL)
+	`
+
+	err := repo.db.GetContext(ctx, &exists, query_is_enabled, userID)
+	if err != nil {
+		return false, fmt.Errorf("error checking two factor: %v", err)
+	}
+
+	return exists, nil
+}
+
+// SaveTwoFactor stores a new unconfirmed secret for the user, replacing any
+// previous one.
+func (repo *TwoFactorRepository) SaveTwoFactor(ctx context.Context, userID uuid.UUID, secret string) (*entity.UserTwoFactor, error) {
+	var r entity.UserTwoFactor
+
+	const query_upsert = `
+		INSERT INTO "user_two_factor" (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret=EXCLUDED.secret, confirmed_at=NULL, last_used_step=0, updated_at=now()
+		RETURNING *
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_upsert, userID, secret)
+	if err != nil {
+		return nil, fmt.Errorf("error saving two factor: %w", err)
+	}
+
+	return &r, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication for the user and
+// replaces their recovery codes in a single transaction.
+func (repo *TwoFactorRepository) ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error confirming two factor: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_confirm = `
+		UPDATE "user_two_factor" SET confirmed_at=now(), last_used_step=$2, updated_at=now()
+		WHERE user_id=$1
+	`
+
+	if _, err := tx.ExecContext(ctx, query_confirm, userID, step); err != nil {
+		return fmt.Errorf("error confirming two factor: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirming two factor: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes invalidates the user's recovery codes and stores new ones.
+func (repo *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error replacing recovery codes: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error replacing recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID, codeHashes []string) error {
+	const query_delete = `
+		DELETE FROM "recovery_code"
+		WHERE user_id=$1
+	`
+
+	if _, err := tx.ExecContext(ctx, query_delete, userID); err != nil {
+		return fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	const query_insert = `
+		INSERT INTO "recovery_code" (user_id, code_hash)
+		VALUES ($1, $2)
+	`
+
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, query_insert, userID, hash); err != nil {
+			return fmt.Errorf("error inserting recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UseTwoFactorStep records the step of an accepted code. It reports false
+// when that step, or a later one, was already used.
+func (repo *TwoFactorRepository) UseTwoFactorStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	const query_use_step = `
+		UPDATE "user_two_factor" SET last_used_step=$2, updated_at=now()
+		WHERE user_id=$1 AND last_used_step<$2
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_use_step, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("error using two factor step: %v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error using two factor step: %v", err)
+	}
+
+	return affected > 0, nil
+}
+
+// UseRecoveryCode marks the matching unused recovery code as used. It
+// reports false when there is no such code.
+func (repo *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	const query_use_code = `
+		UPDATE "recovery_code" SET used_at=now(), updated_at=now()
+		WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_use_code, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("error using recovery code: %v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error using recovery code: %v", err)
+	}
+
+	return affected > 0, nil
+}
+
+// DeleteTwoFactor disables two-factor authentication and drops the user's
+// recovery codes.
+func (repo *TwoFactorRepository) DeleteTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting two factor: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_delete_codes = `
+		DELETE FROM "recovery_code"
+		WHERE user_id=$1
+	`
+
+	if _, err := tx.ExecContext(ctx, query_delete_codes, userID); err != nil {
+		return fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	const query_delete = `
+		DELETE FROM "user_two_factor"
+		WHERE user_id=$1
+	`
+
+	if _, err := tx.ExecContext(ctx, query_delete, userID); err != nil {
+		return fmt.Errorf("error deleting two factor: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error deleting two factor: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *TwoFactorRepository) CreateMFAChallenge(ctx context.Context, r *entity.MFAChallenge) (*entity.MFAChallenge, error) {
+	const query_insert = `
+		INSERT INTO "mfa_challenge" (user_id, token_hash, expired_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := repo.db.QueryRowContext(ctx, query_insert, r.UserID, r.TokenHash, r.ExpiredAt).
+		Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting mfa challenge: %w", err)
+	}
+
+	return r, nil
+}
+
+func (repo *TwoFactorRepository) GetMFAChallengeByTokenHash(ctx context.Context, hash string) (*entity.MFAChallenge, error) {
+	var r entity.MFAChallenge
+
+	const query_find_by_token = `
+		SELECT * FROM "mfa_challenge"
+		WHERE token_hash=$1
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_by_token, hash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting mfa challenge: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *TwoFactorRepository) IncrementMFAChallengeAttempts(ctx context.Context, id uuid.UUID) error {
+	const query_increment = `
+		UPDATE "mfa_challenge" SET attempts=attempts+1, updated_at=now()
+		WHERE id=$1
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_increment, id)
+	if err != nil {
+		return fmt.Errorf("error updating mfa challenge: %v", err)
+	}
+
+	return nil
+}
+
+func (repo *TwoFactorRepository) DeleteMFAChallenge(ctx context.Context, id uuid.UUID) error {
+	const query_delete = `
+		DELETE FROM "mfa_challenge"
+		WHERE id=$1
+	`
+
+	_, err := repo.db.ExecContext(ctx, query_delete, id)
+	if err != nil {
+		return fmt.Errorf("error deleting mfa challenge: %v", err)
+	}
+
+	return nil
+}
+
+// PurgeExpiredMFAChallenges deletes the mfa challenges that expired before the given time.
+func (repo *TwoFactorRepository) PurgeExpiredMFAChallenges(ctx context.Context, before time.Time) (int64, error) {
+	const query_purge = `
+		DELETE FROM "mfa_challenge"
+		WHERE expired_at < $1
+	`
+
+	result, err := repo.db.ExecContext(ctx, query_purge, before)
+	if err != nil {
+		return 0, fmt.Errorf("error purging mfa challenges: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error purging mfa challenges: %w", err)
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmTwoFactor(t *testing.T) {
+	userID := uuid.New()
+	hashes := []string{"hash-1", "hash-2"}
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TwoFactorRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user_two_factor" SET confirmed_at=now(), last_used_step=$2, updated_at=now() WHERE user_id=$1`).
+					WithArgs(userID, int64(42)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM "recovery_code" WHERE user_id=$1`).
+					WithArgs(userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				for _, hash := range hashes {
+					mock.ExpectExec(`INSERT INTO "recovery_code" (user_id, code_hash) VALUES ($1, $2)`).
+						WithArgs(userID, hash).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+
+				err := repo.ConfirmTwoFactor(context.Background(), userID, 42, hashes)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting recovery code",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user_two_factor" SET confirmed_at=now(), last_used_step=$2, updated_at=now() WHERE user_id=$1`).
+					WithArgs(userID, int64(42)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM "recovery_code" WHERE user_id=$1`).
+					WithArgs(userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO "recovery_code" (user_id, code_hash) VALUES ($1, $2)`).
+					WithArgs(userID, hashes[0]).
+					WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+
+				err := repo.ConfirmTwoFactor(context.Background(), userID, 42, hashes)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTwoFactorRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestUseTwoFactorStep(t *testing.T) {
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TwoFactorRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "user_two_factor" SET last_used_step=$2, updated_at=now() WHERE user_id=$1 AND last_used_step<$2`).
+					WithArgs(userID, int64(43)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				used, err := repo.UseTwoFactorStep(context.Background(), userID, 43)
+				require.NoError(t, err)
+				require.True(t, used)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "step already used",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "user_two_factor" SET last_used_step=$2, updated_at=now() WHERE user_id=$1 AND last_used_step<$2`).
+					WithArgs(userID, int64(42)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				used, err := repo.UseTwoFactorStep(context.Background(), userID, 42)
+				require.NoError(t, err)
+				require.False(t, used)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTwoFactorRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	userID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TwoFactorRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "recovery_code" SET used_at=now(), updated_at=now() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`).
+					WithArgs(userID, "hash-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				used, err := repo.UseRecoveryCode(context.Background(), userID, "hash-1")
+				require.NoError(t, err)
+				require.True(t, used)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "code already used",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "recovery_code" SET used_at=now(), updated_at=now() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`).
+					WithArgs(userID, "hash-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				used, err := repo.UseRecoveryCode(context.Background(), userID, "hash-1")
+				require.NoError(t, err)
+				require.False(t, used)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTwoFactorRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestGetMFAChallengeByTokenHash(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TwoFactorRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "user_id", "token_hash", "expired_at", "attempts"}).
+					AddRow(expectedID, time.Now(), time.Now(), uuid.New(), "hash", time.Now().Add(5*time.Minute), 1)
+
+				mock.ExpectQuery(`SELECT * FROM "mfa_challenge" WHERE token_hash=$1`).
+					WithArgs("hash").
+					WillReturnRows(rows)
+
+				record, err := repo.GetMFAChallengeByTokenHash(context.Background(), "hash")
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, 1, record.Attempts)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "challenge not found",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "mfa_challenge" WHERE token_hash=$1`).
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetMFAChallengeByTokenHash(context.Background(), "hash")
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTwoFactorRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestCreateMFAChallenge(t *testing.T) {
+	challenge := &entity.MFAChallenge{
+		UserID:    uuid.New(),
+		TokenHash: "hash",
+		ExpiredAt: time.Now().Add(5 * time.Minute),
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TwoFactorRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "mfa_challenge" (user_id, token_hash, expired_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
+					WithArgs(challenge.UserID, challenge.TokenHash, challenge.ExpiredAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, time.Now(), time.Now()))
+
+				record, err := repo.CreateMFAChallenge(context.Background(), challenge)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting mfa challenge",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "mfa_challenge" (user_id, token_hash, expired_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`).
+					WithArgs(challenge.UserID, challenge.TokenHash, challenge.ExpiredAt).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.CreateMFAChallenge(context.Background(), challenge)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTwoFactorRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestPurgeExpiredMFAChallenges(t *testing.T) {
+	before := time.Now().UTC()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *TwoFactorRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "mfa_challenge" WHERE expired_at < $1`).
+					WithArgs(before).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				purged, err := repo.PurgeExpiredMFAChallenges(context.Background(), before)
+				require.NoError(t, err)
+				require.Equal(t, int64(2), purged)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed purging mfa challenges",
+			test: func(t *testing.T, repo *TwoFactorRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM "mfa_challenge" WHERE expired_at < $1`).
+					WithArgs(before).
+					WillReturnError(fmt.Errorf("some error"))
+
+				_, err := repo.PurgeExpiredMFAChallenges(context.Background(), before)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewTwoFactorRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
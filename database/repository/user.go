@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type UserRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserRepository(db *sqlx.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+func (repo *UserRepository) GetUser(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	var r entity.User
+
+	const query_find_one = `
+		SELECT * FROM "user"
+		WHERE id=$1 AND deleted_at IS NULL
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_one, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", notFound("user", err))
+	}
+
+	return &r, nil
+}
+
+func (repo *UserRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	var r entity.User
+
+	const query_find_by_email = `
+		SELECT * FROM "user"
+		WHERE lower(email)=lower($1) AND deleted_at IS NULL
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_by_email, email)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *UserRepository) GetUserByTokenVerify(ctx context.Context, token string) (*entity.User, error) {
+	var r entity.User
+
+	const query_find_by_token = `
+		SELECT * FROM "user"
+		WHERE token_verify=$1 AND deleted_at IS NULL
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_find_by_token, token)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	return &r, nil
+}
+
+func (repo *UserRepository) CreateUser(ctx context.Context, r *entity.User) (*entity.User, error) {
+	const query_insert = `
+		INSERT INTO "user" (fullname, email, password, phone, token_verify, is_active, is_blocked, role_id, upload_id, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := repo.db.QueryRowContext(ctx, query_insert, r.Fullname, r.Email, r.Password, r.Phone, r.TokenVerify, r.IsActive, r.IsBlocked, r.RoleID, r.UploadID, r.Timezone).
+		Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error inserting user: %w", err)
+	}
+
+	return r, nil
+}
+
+// userColumns are the user fields that can be sorted and filtered on.
+var userColumns = columns{
+	"id":         {expr: "id", filter: filterUUID},
+	"created_at": {expr: "created_at"},
+	"updated_at": {expr: "updated_at"},
+	"fullname":   {expr: "fullname", filter: filterSearch},
+	"email":      {expr: "email", filter: filterSearch},
+	"role_id":    {expr: "role_id", filter: filterUUID},
+	"is_active":  {expr: "is_active", filter: filterBool},
+	"is_blocked": {expr: "is_blocked", filter: filterBool},
+	"timezone":   {expr: "timezone", filter: filterExact},
+}
+
+// ListUsers lists a page of the users, including the soft deleted ones when
+// withDeleted is set, along with the number of users matching.
+func (repo *UserRepository) ListUsers(ctx context.Context, withDeleted bool, opts entity.QueryOptions) ([]entity.User, int, error) {
+	var users []entity.User
+	var total int
+
+	query_find_all, query_count, args, err := listQuery(`"user"`, []string{"($1 OR deleted_at IS NULL)"}, []interface{}{withDeleted}, opts, userColumns, "fullname")
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing users: %w", err)
+	}
+
+	err = repo.db.GetContext(ctx, &total, query_count, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting users: %v", err)
+	}
+
+	err = repo.db.SelectContext(ctx, &users, query_find_all, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing users: %v", err)
+	}
+
+	return users, total, nil
+}
+
+func (repo *UserRepository) UpdateUser(ctx context.Context, r *entity.User) (*entity.User, error) {
+	const query_update = `
+		UPDATE "user" SET fullname=:fullname, email=:email, password=:password, phone=:phone, token_verify=:token_verify,
+		is_active=:is_active, is_blocked=:is_blocked, role_id=:role_id, upload_id=:upload_id, timezone=:timezone, updated_at=:updated_at
+		WHERE id=:id
+	`
+
+	_, err := repo.db.NamedExecContext(ctx, query_update, r)
+	if err != nil {
+		return nil, fmt.Errorf("error updating user: %w", err)
+	}
+
+	return r, nil
+}
+
+// SetPassword stores a new password hash and revokes every session of the
+// user in a single transaction. It returns sql.ErrNoRows when there is no
+// such user.
+func (repo *UserRepository) SetPassword(ctx context.Context, id uuid.UUID, password string) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error setting password: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_update_password = `
+		UPDATE "user" SET password=$2, updated_at=now()
+		WHERE id=$1 AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query_update_password, id, password)
+	if err != nil {
+		return fmt.Errorf("error setting password: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error setting password: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error setting password: %w", notFound("user", sql.ErrNoRows))
+	}
+
+	const query_revoke_sessions = `
+		UPDATE "session" SET revoked_at=now(), updated_at=now()
+		WHERE user_id=$1 AND revoked_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, query_revoke_sessions, id); err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error setting password: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUser soft deletes the user and revokes their sessions in a single
+// transaction. It returns sql.ErrNoRows when there is no such user or it is
+// already deleted.
+func (repo *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_delete = `
+		UPDATE "user" SET deleted_at=now(), updated_at=now()
+		WHERE id=$1 AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query_delete, id)
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error deleting user: %w", notFound("user", sql.ErrNoRows))
+	}
+
+	const query_revoke_sessions = `
+		UPDATE "session" SET revoked_at=now(), updated_at=now()
+		WHERE user_id=$1 AND revoked_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, query_revoke_sessions, id); err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreUser brings back a soft deleted user. Sessions revoked on deletion
+// stay revoked, the user signs in again.
+func (repo *UserRepository) RestoreUser(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	var r entity.User
+
+	const query_restore = `
+		UPDATE "user" SET deleted_at=NULL, updated_at=now()
+		WHERE id=$1 AND deleted_at IS NOT NULL
+		RETURNING *
+	`
+
+	err := repo.db.GetContext(ctx, &r, query_restore, id)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring user: %w", notFound("user", err))
+	}
+
+	return &r, nil
+}
+
+// ForceDeleteUser permanently deletes the user, whether soft deleted or not,
+// together with their sessions. Data owned through cascading keys goes with
+// it; time entries, timesheets and projects block the deletion.
+func (repo *UserRepository) ForceDeleteUser(ctx context.Context, id uuid.UUID) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query_delete_sessions = `
+		DELETE FROM "session"
+		WHERE user_id=$1
+	`
+
+	if _, err := tx.ExecContext(ctx, query_delete_sessions, id); err != nil {
+		return fmt.Errorf("error deleting sessions: %w", err)
+	}
+
+	const query_force_delete = `
+		DELETE FROM "user"
+		WHERE id=$1
+	`
+
+	result, err := tx.ExecContext(ctx, query_force_delete, id)
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("error deleting user: %w", notFound("user", sql.ErrNoRows))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,468 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUser(t *testing.T) {
+	expectedID := uuid.New()
+	roleID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *UserRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "fullname", "email", "password", "phone", "token_verify", "is_active", "is_blocked", "role_id", "upload_id", "timezone"}).
+					AddRow(expectedID, time.Now(), time.Now(), nil, "Test User", "user@example.com", "secret", nil, nil, true, false, roleID, nil, "Asia/Jakarta")
+
+				mock.ExpectQuery(`SELECT * FROM "user" WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnRows(rows)
+
+				record, err := repo.GetUser(context.Background(), expectedID)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.Equal(t, "Asia/Jakarta", record.Timezone)
+				require.Nil(t, record.Phone)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed getting user",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "user" WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnError(fmt.Errorf("error getting user"))
+
+				_, err := repo.GetUser(context.Background(), expectedID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "user not found",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "user" WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetUser(context.Background(), expectedID)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewUserRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	expectedID := uuid.New()
+	email := "user@example.com"
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *UserRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "fullname", "email", "password", "phone", "token_verify", "is_active", "is_blocked", "role_id", "upload_id", "timezone"}).
+					AddRow(expectedID, time.Now(), time.Now(), nil, "Test User", email, "secret", nil, nil, true, false, uuid.New(), nil, "Asia/Jakarta")
+
+				mock.ExpectQuery(`SELECT * FROM "user" WHERE lower(email)=lower($1) AND deleted_at IS NULL`).
+					WithArgs(email).
+					WillReturnRows(rows)
+
+				record, err := repo.GetUserByEmail(context.Background(), email)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "user not found",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "user" WHERE lower(email)=lower($1) AND deleted_at IS NULL`).
+					WithArgs(email).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetUserByEmail(context.Background(), email)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewUserRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestGetUserByTokenVerify(t *testing.T) {
+	expectedID := uuid.New()
+	token := "verify-token"
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *UserRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "fullname", "email", "password", "phone", "token_verify", "is_active", "is_blocked", "role_id", "upload_id", "timezone"}).
+					AddRow(expectedID, time.Now(), time.Now(), nil, "Test User", "user@example.com", "secret", nil, token, false, false, uuid.New(), nil, "Asia/Jakarta")
+
+				mock.ExpectQuery(`SELECT * FROM "user" WHERE token_verify=$1 AND deleted_at IS NULL`).
+					WithArgs(token).
+					WillReturnRows(rows)
+
+				record, err := repo.GetUserByTokenVerify(context.Background(), token)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+				require.False(t, record.IsActive)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "user not found",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT * FROM "user" WHERE token_verify=$1 AND deleted_at IS NULL`).
+					WithArgs(token).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetUserByTokenVerify(context.Background(), token)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewUserRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	token := "verify-token"
+
+	u := &entity.User{
+		Fullname:    "Test User",
+		Email:       "user@example.com",
+		Password:    "hashed",
+		TokenVerify: &token,
+		RoleID:      uuid.New(),
+		Timezone:    "Asia/Jakarta",
+	}
+
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *UserRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "user" (fullname, email, password, phone, token_verify, is_active, is_blocked, role_id, upload_id, timezone) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at`).
+					WithArgs(u.Fullname, u.Email, u.Password, u.Phone, u.TokenVerify, u.IsActive, u.IsBlocked, u.RoleID, u.UploadID, u.Timezone).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(expectedID, time.Now(), time.Now()))
+
+				record, err := repo.CreateUser(context.Background(), u)
+				require.NoError(t, err)
+				require.Equal(t, expectedID, record.ID)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed inserting user",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "user" (fullname, email, password, phone, token_verify, is_active, is_blocked, role_id, upload_id, timezone) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at`).
+					WithArgs(u.Fullname, u.Email, u.Password, u.Phone, u.TokenVerify, u.IsActive, u.IsBlocked, u.RoleID, u.UploadID, u.Timezone).
+					WillReturnError(fmt.Errorf("error inserting user"))
+
+				_, err := repo.CreateUser(context.Background(), u)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewUserRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	u := &entity.User{
+		ID:       uuid.New(),
+		Fullname: "Test User",
+		Email:    "user@example.com",
+		Password: "hashed",
+		IsActive: true,
+		RoleID:   uuid.New(),
+		Timezone: "Asia/Jakarta",
+	}
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *UserRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "user" SET fullname=?, email=?, password=?, phone=?, token_verify=?, is_active=?, is_blocked=?, role_id=?, upload_id=?, timezone=?, updated_at=? WHERE id=?`).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				record, err := repo.UpdateUser(context.Background(), u)
+				require.NoError(t, err)
+				require.True(t, record.IsActive)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "failed updating user",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "user" SET fullname=?, email=?, password=?, phone=?, token_verify=?, is_active=?, is_blocked=?, role_id=?, upload_id=?, timezone=?, updated_at=? WHERE id=?`).
+					WillReturnError(fmt.Errorf("error updating user"))
+
+				_, err := repo.UpdateUser(context.Background(), u)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewUserRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestSetPassword(t *testing.T) {
+	expectedID := uuid.New()
+	password := "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA"
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *UserRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user" SET password=$2, updated_at=now() WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID, password).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=now(), updated_at=now() WHERE user_id=$1 AND revoked_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+
+				err := repo.SetPassword(context.Background(), expectedID, password)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "user not found",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user" SET password=$2, updated_at=now() WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID, password).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+
+				err := repo.SetPassword(context.Background(), expectedID, password)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewUserRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *UserRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user" SET deleted_at=now(), updated_at=now() WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "session" SET revoked_at=now(), updated_at=now() WHERE user_id=$1 AND revoked_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+
+				err := repo.DeleteUser(context.Background(), expectedID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "user already deleted",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user" SET deleted_at=now(), updated_at=now() WHERE id=$1 AND deleted_at IS NULL`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+
+				err := repo.DeleteUser(context.Background(), expectedID)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewUserRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
+
+func TestForceDeleteUser(t *testing.T) {
+	expectedID := uuid.New()
+
+	tcs := []struct {
+		name string
+		test func(*testing.T, *UserRepository, sqlmock.Sqlmock)
+	}{
+		{
+			name: "success",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM "session" WHERE user_id=$1`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(`DELETE FROM "user" WHERE id=$1`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				err := repo.ForceDeleteUser(context.Background(), expectedID)
+				require.NoError(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "user still referenced",
+			test: func(t *testing.T, repo *UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM "session" WHERE user_id=$1`).
+					WithArgs(expectedID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`DELETE FROM "user" WHERE id=$1`).
+					WithArgs(expectedID).
+					WillReturnError(fmt.Errorf("foreign key violation"))
+				mock.ExpectRollback()
+
+				err := repo.ForceDeleteUser(context.Background(), expectedID)
+				require.Error(t, err)
+
+				err = mock.ExpectationsWereMet()
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestDB(t, func(db *sqlx.DB, mock sqlmock.Sqlmock) {
+				repo := NewUserRepository(db)
+				tc.test(t, repo, mock)
+			})
+		})
+	}
+}
@@ -0,0 +1,16 @@
+-- projects owned by the admin, with the user as a member
+INSERT INTO "project" ("id","owner_id","name","description")
+SELECT p.id::uuid, u.id, p.name, p.description
+FROM "user" u, (VALUES
+	('6f1c0a52-3b8e-4d27-9a61-0c4e2f7d8b13','Website Redesign','New layout and content for the company website'),
+	('a84d27e9-5c16-4f03-b2d8-7e9a1c3f5062','Mobile App','Time tracking app for iOS and Android')
+) AS p(id, name, description)
+WHERE u.email='admin@example.com'
+ON CONFLICT ("id") DO NOTHING;
+
+INSERT INTO "project_member" ("project_id","user_id")
+SELECT p.id, u.id
+FROM "project" p, "user" u
+WHERE p.id IN ('6f1c0a52-3b8e-4d27-9a61-0c4e2f7d8b13','a84d27e9-5c16-4f03-b2d8-7e9a1c3f5062')
+AND u.email IN ('admin@example.com','user@example.com')
+ON CONFLICT ("project_id","user_id") DO NOTHING;
@@ -0,0 +1,14 @@
+-- last week's mornings and afternoons of the user, on both projects
+INSERT INTO "time_entry" ("user_id","project_id","started_at","ended_at","duration","description","is_billable")
+SELECT u.id, e.project_id::uuid, d::date + e.started_at, d::date + e.ended_at, extract(epoch FROM e.ended_at - e.started_at)::bigint, e.description, e.is_billable
+FROM "user" u,
+	generate_series(date_trunc('week', now()) - interval '7 days', date_trunc('week', now()) - interval '3 days', interval '1 day') AS d,
+	(VALUES
+		('6f1c0a52-3b8e-4d27-9a61-0c4e2f7d8b13', time '09:00', time '12:00', 'Page designs', true),
+		('a84d27e9-5c16-4f03-b2d8-7e9a1c3f5062', time '13:00', time '17:00', 'App development', true)
+	) AS e(project_id, started_at, ended_at, description, is_billable)
+WHERE u.email='user@example.com'
+AND NOT EXISTS (
+	SELECT 1 FROM "time_entry" t
+	WHERE t.user_id=u.id AND t.started_at=d::date + e.started_at
+);
@@ -0,0 +1,53 @@
+// Package seeds embeds demo data for development databases. Seeds only add
+// rows that are missing, so they can be run again safely.
+package seeds
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Run applies every seed in name order, each in its own transaction, and
+// returns their names.
+func Run(ctx context.Context, db *sqlx.DB) ([]string, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("error reading seeds: %w", err)
+	}
+
+	var done []string
+	for _, name := range names {
+		script, err := fs.ReadFile(files, name)
+		if err != nil {
+			return done, fmt.Errorf("error reading seed %s: %w", name, err)
+		}
+
+		if err := run(ctx, db, string(script)); err != nil {
+			return done, fmt.Errorf("error running seed %s: %w", name, err)
+		}
+		done = append(done, name)
+	}
+
+	return done, nil
+}
+
+func run(ctx context.Context, db *sqlx.DB, script string) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
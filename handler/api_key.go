@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type apiKeyHandler struct {
+	ctx     context.Context
+	service *service.APIKeyService
+}
+
+func NewAPIKeyHandler(service *service.APIKeyService) *apiKeyHandler {
+	return &apiKeyHandler{
+		ctx:     context.Background(),
+		service: service,
+	}
+}
+
+func toStoreAPIKey(r *entity.APIKeyReq) *entity.APIKey {
+	k := &entity.APIKey{
+		Name:   r.Name,
+		Scopes: r.Scopes,
+	}
+
+	if k.Scopes == nil {
+		k.Scopes = []string{}
+	}
+
+	if r.ExpiredAt != nil {
+		expiredAt := r.ExpiredAt.UTC()
+		k.ExpiredAt = &expiredAt
+	}
+
+	return k
+}
+
+func toAPIKeyRes(r *entity.APIKey) entity.APIKeyRes {
+	return entity.APIKeyRes{
+		ID:         r.ID,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+		Name:       r.Name,
+		Prefix:     r.Prefix,
+		Scopes:     r.Scopes,
+		ExpiredAt:  r.ExpiredAt,
+		LastUsedAt: r.LastUsedAt,
+		Key:        r.Key,
+	}
+}
+
+func pathAPIKeyReq(key *entity.APIKey, r entity.APIKeyReq) {
+	if r.Name != "" {
+		key.Name = r.Name
+	}
+
+	if r.Scopes != nil {
+		key.Scopes = r.Scopes
+	}
+
+	if r.ExpiredAt != nil {
+		expiredAt := r.ExpiredAt.UTC()
+		key.ExpiredAt = &expiredAt
+	}
+
+	key.UpdatedAt = time.Now().UTC()
+}
+
+func (h *apiKeyHandler) createAPIKey(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := new(entity.APIKeyReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	record, err := h.service.CreateAPIKey(h.ctx, user, toStoreAPIKey(r))
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "api key has been created, copy it now as it will not be shown again", toAPIKeyRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *apiKeyHandler) getAPIKey(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	record, err := h.service.GetAPIKey(h.ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toAPIKeyRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *apiKeyHandler) listAPIKeys(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	records, err := h.service.ListAPIKeys(h.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	res := []entity.APIKeyRes{}
+	for _, p := range records {
+		res = append(res, toAPIKeyRes(&p))
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", res)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *apiKeyHandler) updateAPIKey(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	// parsing uuid
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	// form validation
+	r := new(entity.APIKeyReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	// get api key by id
+	key, err := h.service.GetAPIKey(h.ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// path update
+	pathAPIKeyReq(key, *r)
+	updated, err := h.service.UpdateAPIKey(h.ctx, user, key)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been updated", toAPIKeyRes(updated))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *apiKeyHandler) deleteAPIKey(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.DeleteAPIKey(h.ctx, id, user.ID); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"gofi/database/entity"
+	"gofi/pkg/constant"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRoutesRequireSession(t *testing.T) {
+	tcs := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			path:   "/api-key/",
+		},
+		{
+			name:   "update its own key",
+			method: http.MethodPut,
+			path:   "/api-key/not-a-uuid",
+		},
+		{
+			name:   "delete",
+			method: http.MethodDelete,
+			path:   "/api-key/not-a-uuid",
+		},
+	}
+
+	for _, tc := range tcs {
+		for principal, session := range map[string]bool{"session": true, "api key": false} {
+			t.Run(tc.name+" with "+principal, func(t *testing.T) {
+				db, _, err := sqlmock.New()
+				require.NoError(t, err)
+				defer db.Close()
+
+				app := fiber.New()
+				app.Use(func(c *fiber.Ctx) error {
+					if session {
+						c.Locals(constant.LocalsSession, &entity.Session{})
+					} else {
+						c.Locals(constant.LocalsAPIKey, &entity.APIKey{Scopes: []string{constant.PermissionSessionRead}})
+					}
+					c.Locals(constant.LocalsUser, &entity.User{})
+					return c.Next()
+				})
+				APIKeyHandler(sqlx.NewDb(db, "sqlmock"), app)
+
+				req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{`))
+				req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+				res, err := app.Test(req)
+				require.NoError(t, err)
+
+				// a session gets past the guard and fails on the request itself
+				if session {
+					require.NotEqual(t, http.StatusForbidden, res.StatusCode)
+				} else {
+					require.Equal(t, http.StatusForbidden, res.StatusCode)
+				}
+			})
+		}
+	}
+}
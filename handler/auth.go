@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type authHandler struct {
+	ctx                  context.Context
+	service              *service.AuthService
+	userService          *service.UserService
+	passwordResetService *service.PasswordResetService
+}
+
+func NewAuthHandler(service *service.AuthService, userService *service.UserService, passwordResetService *service.PasswordResetService) *authHandler {
+	return &authHandler{
+		ctx:                  context.Background(),
+		service:              service,
+		userService:          userService,
+		passwordResetService: passwordResetService,
+	}
+}
+
+func toAuthRes(session *entity.Session, user *entity.User) entity.AuthRes {
+	res := entity.AuthRes{
+		Token:        session.Token,
+		ExpiredAt:    session.ExpiredAt,
+		RefreshToken: session.RefreshToken,
+		User:         toUserRes(user),
+	}
+
+	if session.RefreshExpiredAt != nil {
+		res.RefreshExpiredAt = *session.RefreshExpiredAt
+	}
+
+	return res
+}
+
+func (h *authHandler) signIn(c *fiber.Ctx) error {
+	r := new(entity.SignInReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	session, challenge, user, err := h.service.SignIn(h.ctx, r.Email, r.Password, clientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	// the session is only created once the second step succeeds
+	if challenge != nil {
+		res := entity.MFAChallengeRes{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiredAt:   challenge.ExpiredAt,
+		}
+
+		response := utils.SuccessResponse(http.StatusOK, "two-factor verification required", res)
+		return c.Status(http.StatusOK).JSON(response)
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "sign in successfully", toAuthRes(session, user))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *authHandler) signInTwoFactor(c *fiber.Ctx) error {
+	r := new(entity.TwoFactorSignInReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	session, user, err := h.service.CompleteSignIn(h.ctx, r.MFAToken, r.Code, clientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "sign in successfully", toAuthRes(session, user))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *authHandler) refresh(c *fiber.Ctx) error {
+	r := new(entity.RefreshReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	session, user, err := h.service.Refresh(h.ctx, r.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "session has been refreshed", toAuthRes(session, user))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *authHandler) signUp(c *fiber.Ctx) error {
+	r := new(entity.SignUpReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	user := &entity.User{
+		Fullname: r.Fullname,
+		Email:    r.Email,
+		Timezone: r.Timezone,
+	}
+
+	if r.Phone != "" {
+		user.Phone = &r.Phone
+	}
+
+	record, err := h.userService.SignUp(h.ctx, user, r.Password)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "sign up successfully, please check your email to verify your account", toUserRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *authHandler) verifyEmail(c *fiber.Ctx) error {
+	record, err := h.userService.VerifyEmail(h.ctx, c.Query("token"))
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "email has been verified", toUserRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *authHandler) forgotPassword(c *fiber.Ctx) error {
+	r := new(entity.ForgotPasswordReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	if err := h.passwordResetService.ForgotPassword(h.ctx, r.Email); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "if the email is registered, a password reset link has been sent to it", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *authHandler) resetPassword(c *fiber.Ctx) error {
+	r := new(entity.ResetPasswordReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	if err := h.passwordResetService.ResetPassword(h.ctx, r.Token, r.Password); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "password has been reset, please sign in again", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *authHandler) signOut(c *fiber.Ctx) error {
+	session, ok := authSession(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	if err := h.service.SignOut(h.ctx, session); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "sign out successfully", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *authHandler) me(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toUserRes(user))
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"gofi/config"
+	"gofi/database/entity"
 	"gofi/database/repository"
+	"gofi/middleware"
+	"gofi/pkg/constant"
+	"gofi/pkg/utils"
 	"gofi/service"
+	"slices"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -24,32 +30,307 @@ func isDateValue(date string) bool {
 	return err == nil
 }
 
+// authUser returns the user the auth middleware attached to the request.
+func authUser(c *fiber.Ctx) (*entity.User, bool) {
+	user, ok := c.Locals(constant.LocalsUser).(*entity.User)
+	return user, ok && user != nil
+}
+
+// authSession returns the session the auth middleware attached to the request.
+func authSession(c *fiber.Ctx) (*entity.Session, bool) {
+	session, ok := c.Locals(constant.LocalsSession).(*entity.Session)
+	return session, ok && session != nil
+}
+
+// grantedPermissions returns the permissions the auth middleware attached to the request.
+func grantedPermissions(c *fiber.Ctx) []string {
+	granted, _ := c.Locals(constant.LocalsPermissions).([]string)
+	return granted
+}
+
+// hasPermission reports whether the role of the authenticated user holds the permission.
+func hasPermission(c *fiber.Ctx, permission string) bool {
+	return slices.Contains(grantedPermissions(c), permission)
+}
+
+// clientInfo describes the client of the request, recorded on the sessions it opens.
+func clientInfo(c *fiber.Ctx) entity.ClientInfo {
+	return entity.ClientInfo{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// listMeta describes a page of a list that can be paged by offset or by
+// cursor, whichever the request asked for.
+func listMeta(c *fiber.Ctx, cursorSecret []byte, opts entity.QueryOptions, page entity.Page) interface{} {
+	if opts.Keyset {
+		return utils.NewCursorMeta(c, cursorSecret, opts, page)
+	}
+
+	return utils.NewPageMeta(opts, page.Total)
+}
+
 func RoleHandler(db *sqlx.DB, route fiber.Router) {
 	roleRepo := repository.NewRoleRepository(db)
 	roleService := service.NewRoleService(roleRepo)
 	roleHandler := NewRoleHandler(roleService)
 
+	permissionRepo := repository.NewPermissionRepository(db)
+	permissionService := service.NewPermissionService(permissionRepo, roleRepo)
+	permissionHandler := NewPermissionHandler(permissionService)
+
+	canRead := middleware.RequirePermission(constant.PermissionRoleRead)
+	canManage := middleware.RequirePermission(constant.PermissionRoleManage)
+
 	r := route.Group("/role")
-	r.Get("/", roleHandler.listRoles)
-	r.Post("/", roleHandler.createRole)
+	r.Get("/", canRead, roleHandler.listRoles)
+	r.Post("/", canManage, roleHandler.createRole)
 
 	r_id := r.Group("/:id")
-	r_id.Get("/", roleHandler.getRole)
-	r_id.Put("/", roleHandler.updateRole)
-	r_id.Delete("/", roleHandler.deleteRole)
+	r_id.Get("/", canRead, roleHandler.getRole)
+	r_id.Put("/", canManage, roleHandler.updateRole)
+	r_id.Delete("/", canManage, roleHandler.deleteRole)
+	r_id.Post("/restore", canManage, roleHandler.restoreRole)
+	r_id.Delete("/force", middleware.RequirePermission(constant.PermissionRoleForceDelete), roleHandler.forceDeleteRole)
+
+	r_permission := r_id.Group("/permission")
+	r_permission.Get("/", canRead, permissionHandler.listRolePermissions)
+	r_permission.Post("/", canManage, permissionHandler.grantRolePermission)
+	r_permission.Delete("/:permission_id", canManage, permissionHandler.revokeRolePermission)
+}
+
+func PermissionHandler(db *sqlx.DB, route fiber.Router) {
+	permissionRepo := repository.NewPermissionRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	permissionService := service.NewPermissionService(permissionRepo, roleRepo)
+	permissionHandler := NewPermissionHandler(permissionService)
+
+	r := route.Group("/permission")
+	r.Get("/", middleware.RequirePermission(constant.PermissionRoleRead), permissionHandler.listPermissions)
 }
 
 func SessionHandler(db *sqlx.DB, route fiber.Router) {
 	sessionRepo := repository.NewSessionRepository(db)
 	sessionService := service.NewSessionService(sessionRepo)
-	sessionHandler := NewSessionHandler(sessionService)
+	sessionHandler := NewSessionHandler(sessionService, config.CursorSecret())
+
+	canRead := middleware.RequirePermission(constant.PermissionSessionRead)
+	canManage := middleware.RequirePermission(constant.PermissionSessionManage)
 
 	r := route.Group("/session")
-	r.Get("/", sessionHandler.listSessions)
-	r.Post("/", sessionHandler.createSession)
+	r.Get("/", canRead, sessionHandler.listSessions)
+	r.Post("/", canManage, sessionHandler.createSession)
+
+	r_id := r.Group("/:id")
+	r_id.Get("/", canRead, sessionHandler.getSession)
+	r_id.Put("/", canManage, sessionHandler.updateSession)
+	r_id.Delete("/", canManage, sessionHandler.deleteSession)
+
+	// the caller's own sessions, no permission needed but kept from api keys
+	me := route.Group("/me/sessions", middleware.RequireSession())
+	me.Get("/", sessionHandler.listMySessions)
+	me.Delete("/others", sessionHandler.revokeOtherSessions)
+	me.Delete("/:id", sessionHandler.revokeMySession)
+}
+
+func TimeEntryHandler(db *sqlx.DB, route fiber.Router) {
+	timeEntryRepo := repository.NewTimeEntryRepository(db)
+	timesheetRepo := repository.NewTimesheetRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	timeEntryService := service.NewTimeEntryService(timeEntryRepo, timesheetRepo, projectRepo, userRepo)
+	timeEntryHandler := NewTimeEntryHandler(timeEntryService)
+
+	canRead := middleware.RequireScope(constant.PermissionTimeEntryRead)
+	canWrite := middleware.RequireScope(constant.PermissionTimeEntryWrite)
+
+	r := route.Group("/time-entry")
+	r.Get("/", canRead, timeEntryHandler.listTimeEntries)
+	r.Post("/", canWrite, timeEntryHandler.createTimeEntry)
 
 	r_id := r.Group("/:id")
-	r_id.Get("/", sessionHandler.getSession)
-	r_id.Put("/", sessionHandler.updateSession)
-	r_id.Delete("/", sessionHandler.deleteSession)
+	r_id.Get("/", canRead, timeEntryHandler.getTimeEntry)
+	r_id.Put("/", canWrite, timeEntryHandler.updateTimeEntry)
+	r_id.Delete("/", canWrite, timeEntryHandler.deleteTimeEntry)
+}
+
+func TimerHandler(db *sqlx.DB, route fiber.Router) {
+	timeEntryRepo := repository.NewTimeEntryRepository(db)
+	timesheetRepo := repository.NewTimesheetRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	timerService := service.NewTimerService(timeEntryRepo, timesheetRepo, projectRepo, userRepo)
+	timerHandler := NewTimerHandler(timerService)
+
+	canRead := middleware.RequireScope(constant.PermissionTimeEntryRead)
+	canWrite := middleware.RequireScope(constant.PermissionTimeEntryWrite)
+
+	r := route.Group("/timer")
+	r.Get("/current", canRead, timerHandler.currentTimer)
+	r.Post("/start", canWrite, timerHandler.startTimer)
+	r.Post("/stop", canWrite, timerHandler.stopTimer)
+	r.Post("/resume", canWrite, timerHandler.resumeTimer)
+}
+
+func TimesheetHandler(db *sqlx.DB, route fiber.Router) {
+	timesheetRepo := repository.NewTimesheetRepository(db)
+	timeEntryRepo := repository.NewTimeEntryRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	timesheetService := service.NewTimesheetService(timesheetRepo, timeEntryRepo, permissionRepo, userRepo)
+	timesheetHandler := NewTimesheetHandler(timesheetService)
+
+	canRead := middleware.RequireScope(constant.PermissionTimeEntryRead)
+	canWrite := middleware.RequireScope(constant.PermissionTimeEntryWrite)
+
+	r := route.Group("/timesheet")
+	r.Get("/", canRead, timesheetHandler.listTimesheets)
+	r.Post("/submit", canWrite, timesheetHandler.submitTimesheet)
+
+	r_id := r.Group("/:id")
+	r_id.Get("/", canRead, timesheetHandler.getTimesheet)
+	r_id.Post("/approve", middleware.RequirePermission(constant.PermissionTimesheetApprove), timesheetHandler.approveTimesheet)
+	r_id.Post("/reject", middleware.RequirePermission(constant.PermissionTimesheetApprove), timesheetHandler.rejectTimesheet)
+}
+
+func ProjectHandler(db *sqlx.DB, route fiber.Router) {
+	projectRepo := repository.NewProjectRepository(db)
+	projectService := service.NewProjectService(projectRepo)
+	projectHandler := NewProjectHandler(projectService, config.CursorSecret())
+
+	// owners and members are checked by the service, api keys also need the scope
+	canRead := middleware.RequireScope(constant.PermissionProjectRead)
+	canWrite := middleware.RequireScope(constant.PermissionProjectWrite)
+
+	r := route.Group("/project")
+	r.Get("/", canRead, projectHandler.listProjects)
+	r.Post("/", middleware.RequirePermission(constant.PermissionProjectWrite), projectHandler.createProject)
+
+	r_id := r.Group("/:id")
+	r_id.Get("/", canRead, projectHandler.getProject)
+	r_id.Put("/", canWrite, projectHandler.updateProject)
+	r_id.Delete("/", canWrite, projectHandler.deleteProject)
+	r_id.Post("/restore", canWrite, projectHandler.restoreProject)
+	r_id.Delete("/force", middleware.RequirePermission(constant.PermissionProjectForceDelete), projectHandler.forceDeleteProject)
+
+	r_member := r_id.Group("/member")
+	r_member.Get("/", canRead, projectHandler.listProjectMembers)
+	r_member.Post("/", canWrite, projectHandler.addProjectMember)
+	r_member.Delete("/:user_id", canWrite, projectHandler.removeProjectMember)
+}
+
+func AuthHandler(db *sqlx.DB, route fiber.Router) {
+	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	lockoutRepo := repository.NewAccountLockoutRepository(db)
+	authService := service.NewAuthService(userRepo, sessionRepo, permissionRepo, twoFactorRepo, lockoutRepo, service.AuthOptions{
+		SessionExpiresIn: config.SessionExpiresIn(),
+		RefreshExpiresIn: config.RefreshExpiresIn(),
+		KeySet:           config.JWTKeySet(),
+	})
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	mailer := config.Mailer()
+	userService := service.NewUserService(userRepo, lockoutRepo, permissionRepo, mailer, config.AppURL()+"/v1/auth/verify-email")
+	passwordResetService := service.NewPasswordResetService(passwordResetRepo, userRepo, mailer, config.PasswordResetURL())
+	authHandler := NewAuthHandler(authService, userService, passwordResetService)
+
+	r := route.Group("/auth")
+	r.Post("/sign-up", authHandler.signUp)
+	r.Get("/verify-email", authHandler.verifyEmail)
+	r.Post("/sign-in", authHandler.signIn)
+	r.Post("/sign-in/2fa", authHandler.signInTwoFactor)
+	r.Post("/refresh", authHandler.refresh)
+	r.Post("/forgot-password", authHandler.forgotPassword)
+	r.Post("/reset-password", authHandler.resetPassword)
+	r.Post("/sign-out", authHandler.signOut)
+	r.Get("/me", authHandler.me)
+}
+
+// OIDCHandler registers the single sign-on routes, only when a provider is
+// configured.
+func OIDCHandler(db *sqlx.DB, route fiber.Router) {
+	provider := config.OIDCProvider()
+	if provider == nil {
+		return
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	lockoutRepo := repository.NewAccountLockoutRepository(db)
+	authService := service.NewAuthService(userRepo, sessionRepo, permissionRepo, twoFactorRepo, lockoutRepo, service.AuthOptions{
+		SessionExpiresIn: config.SessionExpiresIn(),
+		RefreshExpiresIn: config.RefreshExpiresIn(),
+		KeySet:           config.JWTKeySet(),
+	})
+	oidcRepo := repository.NewOIDCRepository(db)
+	oidcService := service.NewOIDCService(provider, oidcRepo, userRepo, authService, config.OIDCDefaultRoleID())
+	oidcHandler := NewOIDCHandler(oidcService)
+
+	r := route.Group("/auth/oidc")
+	r.Get("/login", oidcHandler.login)
+	r.Get("/callback", oidcHandler.callback)
+}
+
+func TwoFactorHandler(db *sqlx.DB, route fiber.Router) {
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, roleRepo, config.AppName())
+	twoFactorHandler := NewTwoFactorHandler(twoFactorService)
+
+	// enrolling or disabling a second factor takes a signed-in session
+	r := route.Group("/auth/2fa", middleware.RequireSession())
+	r.Post("/enroll", twoFactorHandler.enroll)
+	r.Post("/confirm", twoFactorHandler.confirm)
+	r.Post("/disable", twoFactorHandler.disable)
+	r.Post("/recovery-codes", twoFactorHandler.regenerateRecoveryCodes)
+}
+
+func UserHandler(db *sqlx.DB, route fiber.Router) {
+	userRepo := repository.NewUserRepository(db)
+	lockoutRepo := repository.NewAccountLockoutRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	userService := service.NewUserService(userRepo, lockoutRepo, permissionRepo, config.Mailer(), config.AppURL()+"/v1/auth/verify-email")
+	userHandler := NewUserHandler(userService)
+
+	r := route.Group("/user", middleware.RequirePermission(constant.PermissionUserManage))
+	r.Get("/", userHandler.listUsers)
+	r.Post("/", userHandler.createUser)
+
+	r_id := r.Group("/:id")
+	r_id.Get("/", userHandler.getUser)
+	r_id.Put("/", userHandler.updateUser)
+	r_id.Delete("/", userHandler.deleteUser)
+	r_id.Post("/restore", userHandler.restoreUser)
+	r_id.Delete("/force", middleware.RequirePermission(constant.PermissionUserForceDelete), userHandler.forceDeleteUser)
+	r_id.Get("/lockout", userHandler.listAccountLockouts)
+	r_id.Post("/unlock", userHandler.unlockUser)
+}
+
+func APIKeyHandler(db *sqlx.DB, route fiber.Router) {
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, permissionRepo)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService)
+
+	// a leaked key must not be able to mint, widen or revoke keys
+	r := route.Group("/api-key", middleware.RequireSession())
+	r.Get("/", apiKeyHandler.listAPIKeys)
+	r.Post("/", apiKeyHandler.createAPIKey)
+
+	r_id := r.Group("/:id")
+	r_id.Get("/", apiKeyHandler.getAPIKey)
+	r_id.Put("/", apiKeyHandler.updateAPIKey)
+	r_id.Delete("/", apiKeyHandler.deleteAPIKey)
+}
+
+func JWKSHandler(route fiber.Router) {
+	jwksHandler := NewJWKSHandler(config.JWTKeySet())
+
+	route.Get("/.well-known/jwks.json", jwksHandler.jwks)
 }
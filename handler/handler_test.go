@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"gofi/database/entity"
+	"gofi/middleware"
+	"gofi/pkg/constant"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeTrackingRoutesRequireScope(t *testing.T) {
+	tcs := []struct {
+		name   string
+		method string
+		path   string
+		// the only scope of the key, not the one the route needs
+		scope string
+	}{
+		{
+			name:   "list time entries",
+			method: http.MethodGet,
+			path:   "/time-entry/",
+			scope:  constant.PermissionTimeEntryWrite,
+		},
+		{
+			name:   "update time entry",
+			method: http.MethodPut,
+			path:   "/time-entry/not-a-uuid",
+			scope:  constant.PermissionTimeEntryRead,
+		},
+		{
+			name:   "current timer",
+			method: http.MethodGet,
+			path:   "/timer/current",
+			scope:  constant.PermissionTimeEntryWrite,
+		},
+		{
+			name:   "start timer",
+			method: http.MethodPost,
+			path:   "/timer/start",
+			scope:  constant.PermissionTimeEntryRead,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			db, _, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				scopes := []string{tc.scope}
+				c.Locals(constant.LocalsAPIKey, &entity.APIKey{Scopes: scopes})
+				c.Locals(constant.LocalsUser, &entity.User{})
+				c.Locals(constant.LocalsPermissions, scopes)
+				return c.Next()
+			})
+			TimeEntryHandler(sqlx.NewDb(db, "sqlmock"), app)
+			TimerHandler(sqlx.NewDb(db, "sqlmock"), app)
+
+			res, err := app.Test(httptest.NewRequest(tc.method, tc.path, nil))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusForbidden, res.StatusCode)
+		})
+	}
+}
+
+// withEnvFile runs the test from a directory holding an empty .env, which
+// handlers reading their configuration at registration need.
+func withEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), nil, 0o644))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestProjectRoutesRequireScope(t *testing.T) {
+	withEnvFile(t)
+
+	tcs := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{
+			name:   "list projects",
+			method: http.MethodGet,
+			path:   "/project/",
+		},
+		{
+			name:   "get project",
+			method: http.MethodGet,
+			path:   "/project/not-a-uuid",
+		},
+		{
+			name:   "update project",
+			method: http.MethodPut,
+			path:   "/project/not-a-uuid",
+		},
+		{
+			name:   "delete project",
+			method: http.MethodDelete,
+			path:   "/project/not-a-uuid",
+		},
+		{
+			name:   "restore project",
+			method: http.MethodPost,
+			path:   "/project/not-a-uuid/restore",
+		},
+		{
+			name:   "list members",
+			method: http.MethodGet,
+			path:   "/project/not-a-uuid/member",
+		},
+		{
+			name:   "add member",
+			method: http.MethodPost,
+			path:   "/project/not-a-uuid/member",
+		},
+		{
+			name:   "remove member",
+			method: http.MethodDelete,
+			path:   "/project/not-a-uuid/member/not-a-uuid",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			db, _, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				// a key scoped to time tracking only
+				scopes := []string{constant.PermissionTimeEntryRead, constant.PermissionTimeEntryWrite}
+				c.Locals(constant.LocalsAPIKey, &entity.APIKey{Scopes: scopes})
+				c.Locals(constant.LocalsUser, &entity.User{})
+				c.Locals(constant.LocalsPermissions, scopes)
+				return c.Next()
+			})
+			ProjectHandler(sqlx.NewDb(db, "sqlmock"), app)
+
+			res, err := app.Test(httptest.NewRequest(tc.method, tc.path, nil))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusForbidden, res.StatusCode)
+		})
+	}
+}
+
+func TestCreateUserRejectsRoleAboveCaller(t *testing.T) {
+	withEnvFile(t)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	roleID := uuid.New()
+	mock.ExpectQuery(`SELECT p.name FROM "permission" p`).WithArgs(roleID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(constant.PermissionUserManage).
+			AddRow(constant.PermissionRoleManage))
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(constant.LocalsUser, &entity.User{})
+		c.Locals(constant.LocalsPermissions, []string{constant.PermissionUserManage})
+		return c.Next()
+	})
+	UserHandler(sqlx.NewDb(db, "sqlmock"), app)
+
+	body := fmt.Sprintf(`{"fullname":"User","email":"user@example.com","password":"password","role_id":%q}`, roleID)
+	req := httptest.NewRequest(http.MethodPost, "/user/", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTimerActionsWithoutBody(t *testing.T) {
+	tcs := []struct {
+		name    string
+		path    string
+		status  int
+		message string
+	}{
+		{
+			name:    "stop without running timer",
+			path:    "/timer/stop",
+			status:  http.StatusNotFound,
+			message: "there is no running timer",
+		},
+		{
+			name:    "stop with timezone in query",
+			path:    "/timer/stop?timezone=Nowhere/Invalid",
+			status:  http.StatusUnprocessableEntity,
+			message: "unknown timezone",
+		},
+		{
+			name:    "resume with timezone in query",
+			path:    "/timer/resume?timezone=Nowhere/Invalid",
+			status:  http.StatusUnprocessableEntity,
+			message: "unknown timezone",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT`).WillReturnError(sql.ErrNoRows)
+
+			app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals(constant.LocalsUser, &entity.User{Timezone: "UTC"})
+				return c.Next()
+			})
+			TimerHandler(sqlx.NewDb(db, "sqlmock"), app)
+
+			res, err := app.Test(httptest.NewRequest(http.MethodPost, tc.path, nil))
+			require.NoError(t, err)
+			require.Equal(t, tc.status, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Contains(t, string(body), tc.message)
+		})
+	}
+}
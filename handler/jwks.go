@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"gofi/pkg/jwt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type jwksHandler struct {
+	keySet *jwt.KeySet
+}
+
+func NewJWKSHandler(keySet *jwt.KeySet) *jwksHandler {
+	return &jwksHandler{
+		keySet: keySet,
+	}
+}
+
+// jwks serves the public signing keys as a plain JWKS document, as expected
+// by JWT libraries, rather than in the usual response envelope.
+func (h *jwksHandler) jwks(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+
+	if h.keySet == nil {
+		return c.Status(http.StatusOK).JSON(fiber.Map{"keys": []any{}})
+	}
+
+	return c.Status(http.StatusOK).JSON(h.keySet.JWKS())
+}
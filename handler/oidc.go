@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type oidcHandler struct {
+	ctx     context.Context
+	service *service.OIDCService
+}
+
+func NewOIDCHandler(service *service.OIDCService) *oidcHandler {
+	return &oidcHandler{
+		ctx:     context.Background(),
+		service: service,
+	}
+}
+
+func (h *oidcHandler) login(c *fiber.Ctx) error {
+	url, err := h.service.Login(h.ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.Redirect(url, http.StatusFound)
+}
+
+func (h *oidcHandler) callback(c *fiber.Ctx) error {
+	// the provider reports a denied or failed sign-on in the error parameter
+	if reason := c.Query("error"); reason != "" {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{reason, c.Query("error_description")})
+	}
+
+	session, challenge, user, err := h.service.Callback(h.ctx, c.Query("code"), c.Query("state"), clientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	// the session is only created once the second step succeeds
+	if challenge != nil {
+		res := entity.MFAChallengeRes{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiredAt:   challenge.ExpiredAt,
+		}
+
+		response := utils.SuccessResponse(http.StatusOK, "two-factor verification required", res)
+		return c.Status(http.StatusOK).JSON(response)
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "sign in successfully", toAuthRes(session, user))
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type permissionHandler struct {
+	ctx     context.Context
+	service *service.PermissionService
+}
+
+func NewPermissionHandler(service *service.PermissionService) *permissionHandler {
+	return &permissionHandler{
+		ctx:     context.Background(),
+		service: service,
+	}
+}
+
+func toPermissionRes(r *entity.Permission) entity.PermissionRes {
+	return entity.PermissionRes{
+		ID:          r.ID,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		Name:        r.Name,
+		Description: r.Description,
+	}
+}
+
+func toPermissionResList(permissions []entity.Permission) []entity.PermissionRes {
+	res := []entity.PermissionRes{}
+	for _, p := range permissions {
+		res = append(res, toPermissionRes(&p))
+	}
+
+	return res
+}
+
+func (h *permissionHandler) listPermissions(c *fiber.Ctx) error {
+	permissions, err := h.service.ListPermissions(h.ctx)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toPermissionResList(permissions))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *permissionHandler) listRolePermissions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	permissions, err := h.service.ListRolePermissions(h.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toPermissionResList(permissions))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *permissionHandler) grantRolePermission(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	r := new(entity.RolePermissionReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	permissions, err := h.service.GrantPermission(h.ctx, id, r.PermissionID)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been added", toPermissionResList(permissions))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *permissionHandler) revokeRolePermission(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	permissionID, err := uuid.Parse(c.Params("permission_id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.RevokePermission(h.ctx, id, permissionID); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
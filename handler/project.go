@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+	"gofi/pkg/constant"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type projectHandler struct {
+	ctx          context.Context
+	service      *service.ProjectService
+	cursorSecret []byte
+}
+
+func NewProjectHandler(service *service.ProjectService, cursorSecret []byte) *projectHandler {
+	return &projectHandler{
+		ctx:          context.Background(),
+		service:      service,
+		cursorSecret: cursorSecret,
+	}
+}
+
+func toStoreProject(r *entity.ProjectReq, ownerID uuid.UUID) *entity.Project {
+	return &entity.Project{
+		OwnerID:     ownerID,
+		Name:        r.Name,
+		Description: r.Description,
+	}
+}
+
+func toProjectRes(r *entity.Project) entity.ProjectRes {
+	return entity.ProjectRes{
+		ID:          r.ID,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		DeletedAt:   r.DeletedAt,
+		OwnerID:     r.OwnerID,
+		Name:        r.Name,
+		Description: r.Description,
+	}
+}
+
+func toProjectMemberRes(r *entity.ProjectMember) entity.ProjectMemberRes {
+	return entity.ProjectMemberRes{
+		ID:        r.ID,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		ProjectID: r.ProjectID,
+		UserID:    r.UserID,
+	}
+}
+
+func pathProjectReq(project *entity.Project, r entity.ProjectReq) {
+	if r.Name != "" {
+		project.Name = r.Name
+	}
+
+	if r.Description != "" {
+		project.Description = r.Description
+	}
+
+	project.UpdatedAt = toTimePtr(time.Now())
+}
+
+func (h *projectHandler) createProject(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := new(entity.ProjectReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	record, err := h.service.CreateProject(h.ctx, toStoreProject(r, user.ID))
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been added", toProjectRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) getProject(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	record, err := h.service.GetProject(h.ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toProjectRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) listProjects(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	// deleted projects are only shown to those who can purge them
+	withDeleted := c.QueryBool("with_deleted")
+	if withDeleted && !hasPermission(c, constant.PermissionProjectForceDelete) {
+		errFiber := fiber.NewError(http.StatusForbidden)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{fmt.Sprintf("missing permission %q", constant.PermissionProjectForceDelete)})
+	}
+
+	opts, err := utils.ParseQueryOptions(c)
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := utils.ParseCursor(c, h.cursorSecret, &opts); err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	projects, page, err := h.service.ListProjects(h.ctx, user.ID, withDeleted, opts)
+	if err != nil {
+		return err
+	}
+
+	var res []entity.ProjectRes
+	for _, p := range projects {
+		res = append(res, toProjectRes(&p))
+	}
+
+	response := utils.SuccessPageResponse(http.StatusOK, "data has been received", res, listMeta(c, h.cursorSecret, opts, page))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) updateProject(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	// parsing uuid
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	// form validation
+	r := new(entity.ProjectReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	// get project owned by the user
+	project, err := h.service.GetOwnedProject(h.ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// path update
+	pathProjectReq(project, *r)
+	updated, err := h.service.UpdateProject(h.ctx, project)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been updated", toProjectRes(updated))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) deleteProject(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	// those who can purge projects can also delete and restore anyone's
+	anyOwner := hasPermission(c, constant.PermissionProjectForceDelete)
+	if err := h.service.DeleteProject(h.ctx, id, user.ID, anyOwner); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) restoreProject(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	anyOwner := hasPermission(c, constant.PermissionProjectForceDelete)
+	record, err := h.service.RestoreProject(h.ctx, id, user.ID, anyOwner)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been restored", toProjectRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) forceDeleteProject(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.ForceDeleteProject(h.ctx, id); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been permanently deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) listProjectMembers(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	members, err := h.service.ListProjectMembers(h.ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+
+	var res []entity.ProjectMemberRes
+	for _, m := range members {
+		res = append(res, toProjectMemberRes(&m))
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", res)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) addProjectMember(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	r := new(entity.ProjectMemberReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	record, err := h.service.AddProjectMember(h.ctx, id, user.ID, r.UserID)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been added", toProjectMemberRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *projectHandler) removeProjectMember(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	memberID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.RemoveProjectMember(h.ctx, id, user.ID, memberID); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
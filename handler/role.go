@@ -2,7 +2,9 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"gofi/database/entity"
+	"gofi/pkg/constant"
 	"gofi/pkg/utils"
 	"gofi/service"
 	"net/http"
@@ -25,18 +27,25 @@ func NewRoleHandler(service *service.RoleService) *roleHandler {
 }
 
 func toStoreRole(r *entity.RoleReq) *entity.Role {
-	return &entity.Role{
+	role := &entity.Role{
 		Name: r.Name,
 	}
+
+	if r.Require2FA != nil {
+		role.Require2FA = *r.Require2FA
+	}
+
+	return role
 }
 
 func toRoleRes(r *entity.Role) entity.RoleRes {
 	return entity.RoleRes{
-		ID:        r.ID,
-		CreatedAt: r.CreatedAt,
-		UpdatedAt: r.UpdatedAt,
-		DeletedAt: r.DeletedAt,
-		Name:      r.Name,
+		ID:         r.ID,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+		DeletedAt:  r.DeletedAt,
+		Name:       r.Name,
+		Require2FA: r.Require2FA,
 	}
 }
 
@@ -45,21 +54,22 @@ func pathRoleReq(role *entity.Role, r entity.RoleReq) {
 		role.Name = r.Name
 	}
 
+	if r.Require2FA != nil {
+		role.Require2FA = *r.Require2FA
+	}
+
 	role.UpdatedAt = toTimePtr(time.Now())
 }
 
 func (h *roleHandler) createRole(c *fiber.Ctx) error {
 	r := new(entity.RoleReq)
 	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
-		response := utils.FailureResponse(code, message, errors)
-		return c.Status(int(code)).JSON(response)
+		return utils.SendFailure(c, code, message, errors)
 	}
 
 	record, err := h.service.CreateRole(h.ctx, toStoreRole(r))
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	response := utils.SuccessResponse(http.StatusOK, "data has been added", record)
@@ -70,15 +80,12 @@ func (h *roleHandler) getRole(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		errFiber := fiber.NewError(http.StatusBadRequest)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
 	}
 
 	record, err := h.service.GetRole(h.ctx, id)
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	response := utils.SuccessResponse(http.StatusOK, "data has been received", record)
@@ -86,11 +93,22 @@ func (h *roleHandler) getRole(c *fiber.Ctx) error {
 }
 
 func (h *roleHandler) listRoles(c *fiber.Ctx) error {
-	roles, err := h.service.ListRoles(h.ctx)
+	// deleted roles are only shown to those who can restore them
+	withDeleted := c.QueryBool("with_deleted")
+	if withDeleted && !hasPermission(c, constant.PermissionRoleManage) {
+		errFiber := fiber.NewError(http.StatusForbidden)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{fmt.Sprintf("missing permission %q", constant.PermissionRoleManage)})
+	}
+
+	opts, err := utils.ParseQueryOptions(c)
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	roles, total, err := h.service.ListRoles(h.ctx, withDeleted, opts)
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	var res []entity.RoleRes
@@ -98,7 +116,7 @@ func (h *roleHandler) listRoles(c *fiber.Ctx) error {
 		res = append(res, toRoleRes(&p))
 	}
 
-	response := utils.SuccessResponse(http.StatusOK, "data has been received", res)
+	response := utils.SuccessPageResponse(http.StatusOK, "data has been received", res, utils.NewPageMeta(opts, total))
 	return c.Status(http.StatusOK).JSON(response)
 }
 
@@ -107,32 +125,26 @@ func (h *roleHandler) updateRole(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		errFiber := fiber.NewError(http.StatusBadRequest)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
 	}
 
 	// form validation
 	r := new(entity.RoleReq)
 	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
-		response := utils.FailureResponse(code, message, errors)
-		return c.Status(int(code)).JSON(response)
+		return utils.SendFailure(c, code, message, errors)
 	}
 
 	// get role by id
 	role, err := h.service.GetRole(h.ctx, id)
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	// path update
 	pathRoleReq(role, *r)
 	updated, err := h.service.UpdateRole(h.ctx, role)
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	response := utils.SuccessResponse(http.StatusOK, "data has been updated", updated)
@@ -143,16 +155,44 @@ func (h *roleHandler) deleteRole(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		errFiber := fiber.NewError(http.StatusBadRequest)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
 	}
 
 	if err := h.service.DeleteRole(h.ctx, id); err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	response := utils.SuccessResponse(http.StatusOK, "data has been deleted", nil)
 	return c.Status(http.StatusOK).JSON(response)
 }
+
+func (h *roleHandler) restoreRole(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	record, err := h.service.RestoreRole(h.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been restored", toRoleRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *roleHandler) forceDeleteRole(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.ForceDeleteRole(h.ctx, id); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been permanently deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
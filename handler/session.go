@@ -13,14 +13,16 @@ import (
 )
 
 type sessionHandler struct {
-	ctx     context.Context
-	service *service.SessionService
+	ctx          context.Context
+	service      *service.SessionService
+	cursorSecret []byte
 }
 
-func NewSessionHandler(service *service.SessionService) *sessionHandler {
+func NewSessionHandler(service *service.SessionService, cursorSecret []byte) *sessionHandler {
 	return &sessionHandler{
-		ctx:     context.Background(),
-		service: service,
+		ctx:          context.Background(),
+		service:      service,
+		cursorSecret: cursorSecret,
 	}
 }
 
@@ -34,12 +36,17 @@ func toStoreSession(s *entity.SessionReq) *entity.Session {
 
 func toSessionRes(s *entity.Session) entity.SessionRes {
 	return entity.SessionRes{
-		ID:        s.ID,
-		CreatedAt: s.CreatedAt,
-		UpdatedAt: s.UpdatedAt,
-		UserID:    s.UserID,
-		Token:     s.Token,
-		ExpiredAt: s.ExpiredAt,
+		ID:               s.ID,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
+		UserID:           s.UserID,
+		ExpiredAt:        s.ExpiredAt,
+		RefreshExpiredAt: s.RefreshExpiredAt,
+		FamilyID:         s.FamilyID,
+		RevokedAt:        s.RevokedAt,
+		IPAddress:        s.IPAddress,
+		UserAgent:        s.UserAgent,
+		Device:           s.Device,
 	}
 }
 
@@ -62,18 +69,15 @@ func pathSessionReq(session *entity.Session, s entity.SessionReq) {
 func (h *sessionHandler) createSession(c *fiber.Ctx) error {
 	input := new(entity.SessionReq)
 	if code, message, errors := utils.ParseFormDataAndValidate(c, input); errors != nil {
-		response := utils.FailureResponse(code, message, errors)
-		return c.Status(int(code)).JSON(response)
+		return utils.SendFailure(c, code, message, errors)
 	}
 
 	record, err := h.service.CreateSession(h.ctx, toStoreSession(input))
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
-	response := utils.SuccessResponse(http.StatusOK, "data has been added", record)
+	response := utils.SuccessResponse(http.StatusOK, "data has been added", toSessionRes(record))
 	return c.Status(http.StatusOK).JSON(response)
 }
 
@@ -83,35 +87,41 @@ func (h *sessionHandler) getSession(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		errFiber := fiber.NewError(http.StatusBadRequest)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
 	}
 
 	record, err := h.service.GetSession(h.ctx, id, token)
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
-	response := utils.SuccessResponse(http.StatusOK, "data has been received", record)
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toSessionRes(record))
 	return c.Status(http.StatusOK).JSON(response)
 }
 
 func (h *sessionHandler) listSessions(c *fiber.Ctx) error {
-	roles, err := h.service.ListSessions(h.ctx)
+	opts, err := utils.ParseQueryOptions(c)
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := utils.ParseCursor(c, h.cursorSecret, &opts); err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	sessions, page, err := h.service.ListSessions(h.ctx, opts)
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	var res []entity.SessionRes
-	for _, p := range roles {
+	for _, p := range sessions {
 		res = append(res, toSessionRes(&p))
 	}
 
-	response := utils.SuccessResponse(http.StatusOK, "data has been received", res)
+	response := utils.SuccessPageResponse(http.StatusOK, "data has been received", res, listMeta(c, h.cursorSecret, opts, page))
 	return c.Status(http.StatusOK).JSON(response)
 }
 
@@ -120,35 +130,29 @@ func (h *sessionHandler) updateSession(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		errFiber := fiber.NewError(http.StatusBadRequest)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
 	}
 
 	// form validation
 	input := new(entity.SessionReq)
 	if code, message, errors := utils.ParseFormDataAndValidate(c, input); errors != nil {
-		response := utils.FailureResponse(code, message, errors)
-		return c.Status(int(code)).JSON(response)
+		return utils.SendFailure(c, code, message, errors)
 	}
 
 	// get session by id
 	session, err := h.service.GetSession(h.ctx, id, input.Token)
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	// path update
 	pathSessionReq(session, *input)
 	updated, err := h.service.UpdateSession(h.ctx, session)
 	if err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
-	response := utils.SuccessResponse(http.StatusOK, "data has been updated", updated)
+	response := utils.SuccessResponse(http.StatusOK, "data has been updated", toSessionRes(updated))
 	return c.Status(http.StatusOK).JSON(response)
 }
 
@@ -156,16 +160,83 @@ func (h *sessionHandler) deleteSession(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		errFiber := fiber.NewError(http.StatusBadRequest)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
 	}
 
 	if err := h.service.DeleteSession(h.ctx, id); err != nil {
-		errFiber := fiber.NewError(http.StatusInternalServerError)
-		response := utils.FailureResponse(int32(errFiber.Code), errFiber.Message, []string{err.Error()})
-		return c.Status(errFiber.Code).JSON(response)
+		return err
 	}
 
 	response := utils.SuccessResponse(http.StatusOK, "data has been deleted", nil)
 	return c.Status(http.StatusOK).JSON(response)
 }
+
+func (h *sessionHandler) listMySessions(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	records, err := h.service.ListUserSessions(h.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// requests made with an api key have no current session
+	current, _ := authSession(c)
+
+	res := []entity.SessionRes{}
+	for _, p := range records {
+		item := toSessionRes(&p)
+		item.Current = current != nil && p.FamilyID == current.FamilyID
+		res = append(res, item)
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", res)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *sessionHandler) revokeMySession(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.RevokeUserSession(h.ctx, id, user.ID); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "session has been revoked", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *sessionHandler) revokeOtherSessions(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	// "others" is relative to the session making the request
+	current, ok := authSession(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusForbidden)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"other sessions can only be revoked from a signed-in session"})
+	}
+
+	revoked, err := h.service.RevokeOtherSessions(h.ctx, user.ID, current)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "other sessions have been revoked", fiber.Map{"revoked": revoked})
+	return c.Status(http.StatusOK).JSON(response)
+}
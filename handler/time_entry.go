@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type timeEntryHandler struct {
+	ctx     context.Context
+	service *service.TimeEntryService
+}
+
+func NewTimeEntryHandler(service *service.TimeEntryService) *timeEntryHandler {
+	return &timeEntryHandler{
+		ctx:     context.Background(),
+		service: service,
+	}
+}
+
+// toStoreTimeEntry converts the request, the timestamps are kept in UTC like
+// the ones of the timer since the columns carry no timezone.
+func toStoreTimeEntry(r *entity.TimeEntryReq, userID uuid.UUID) *entity.TimeEntry {
+	endedAt := r.EndedAt.UTC()
+
+	t := &entity.TimeEntry{
+		UserID:      userID,
+		ProjectID:   r.ProjectID,
+		StartedAt:   r.StartedAt.UTC(),
+		EndedAt:     &endedAt,
+		Description: r.Description,
+	}
+
+	if r.IsBillable != nil {
+		t.IsBillable = *r.IsBillable
+	}
+
+	return t
+}
+
+func toTimeEntryRes(r *entity.TimeEntry) entity.TimeEntryRes {
+	return entity.TimeEntryRes{
+		ID:          r.ID,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		DeletedAt:   r.DeletedAt,
+		UserID:      r.UserID,
+		ProjectID:   r.ProjectID,
+		StartedAt:   r.StartedAt,
+		EndedAt:     r.EndedAt,
+		Duration:    r.Duration,
+		Description: r.Description,
+		IsBillable:  r.IsBillable,
+	}
+}
+
+func pathTimeEntryReq(entry *entity.TimeEntry, r entity.TimeEntryReq) {
+	if r.ProjectID != uuid.Nil {
+		entry.ProjectID = r.ProjectID
+	}
+
+	if !r.StartedAt.IsZero() {
+		entry.StartedAt = r.StartedAt.UTC()
+	}
+
+	if !r.EndedAt.IsZero() {
+		endedAt := r.EndedAt.UTC()
+		entry.EndedAt = &endedAt
+	}
+
+	if r.Description != "" {
+		entry.Description = r.Description
+	}
+
+	if r.IsBillable != nil {
+		entry.IsBillable = *r.IsBillable
+	}
+
+	entry.UpdatedAt = toTimePtr(time.Now())
+}
+
+func (h *timeEntryHandler) createTimeEntry(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := new(entity.TimeEntryReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	record, err := h.service.CreateTimeEntry(h.ctx, toStoreTimeEntry(r, user.ID))
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been added", toTimeEntryRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timeEntryHandler) getTimeEntry(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	record, err := h.service.GetTimeEntry(h.ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toTimeEntryRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timeEntryHandler) listTimeEntries(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	opts, err := utils.ParseQueryOptions(c)
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	entries, total, err := h.service.ListTimeEntries(h.ctx, user.ID, opts)
+	if err != nil {
+		return err
+	}
+
+	var res []entity.TimeEntryRes
+	for _, p := range entries {
+		res = append(res, toTimeEntryRes(&p))
+	}
+
+	response := utils.SuccessPageResponse(http.StatusOK, "data has been received", res, utils.NewPageMeta(opts, total))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timeEntryHandler) updateTimeEntry(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	// parsing uuid
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	// form validation
+	r := new(entity.TimeEntryReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	// get time entry by id
+	entry, err := h.service.GetTimeEntry(h.ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// path update
+	pathTimeEntryReq(entry, *r)
+	updated, err := h.service.UpdateTimeEntry(h.ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been updated", toTimeEntryRes(updated))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timeEntryHandler) deleteTimeEntry(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.DeleteTimeEntry(h.ctx, id, user.ID); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
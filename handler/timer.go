@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type timerHandler struct {
+	ctx     context.Context
+	service *service.TimerService
+}
+
+func NewTimerHandler(service *service.TimerService) *timerHandler {
+	return &timerHandler{
+		ctx:     context.Background(),
+		service: service,
+	}
+}
+
+func toStoreTimer(r *entity.TimerReq, userID uuid.UUID) *entity.TimeEntry {
+	t := &entity.TimeEntry{
+		UserID:      userID,
+		ProjectID:   r.ProjectID,
+		Description: r.Description,
+	}
+
+	if r.IsBillable != nil {
+		t.IsBillable = *r.IsBillable
+	}
+
+	return t
+}
+
+// toTimerRes presents the entry in the given location, with the elapsed time
+// measured up to now for a running timer or up to its end otherwise.
+func toTimerRes(r *entity.TimeEntry, loc *time.Location) entity.TimerRes {
+	end := time.Now()
+	if r.EndedAt != nil {
+		end = *r.EndedAt
+	}
+
+	elapsed := int64(end.Sub(r.StartedAt).Seconds())
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	res := entity.TimerRes{
+		ID:          r.ID,
+		UserID:      r.UserID,
+		ProjectID:   r.ProjectID,
+		StartedAt:   r.StartedAt.In(loc),
+		Description: r.Description,
+		IsBillable:  r.IsBillable,
+		IsRunning:   r.EndedAt == nil,
+		Elapsed:     elapsed,
+		ElapsedText: fmt.Sprintf("%02d:%02d:%02d", elapsed/3600, elapsed%3600/60, elapsed%60),
+		Timezone:    loc.String(),
+	}
+
+	if r.EndedAt != nil {
+		endedAt := r.EndedAt.In(loc)
+		res.EndedAt = &endedAt
+	}
+
+	return res
+}
+
+func (h *timerHandler) startTimer(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := new(entity.TimerReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	loc, err := h.service.Location(user, r.Timezone)
+	if err != nil {
+		return err
+	}
+
+	record, err := h.service.StartTimer(h.ctx, toStoreTimer(r, user.ID))
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "timer has been started", toTimerRes(record, loc))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timerHandler) stopTimer(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := &entity.TimerActionReq{Timezone: c.Query("timezone")}
+	if len(c.Body()) > 0 {
+		if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+			return utils.SendFailure(c, code, message, errors)
+		}
+	}
+
+	loc, err := h.service.Location(user, r.Timezone)
+	if err != nil {
+		return err
+	}
+
+	record, err := h.service.StopTimer(h.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "timer has been stopped", toTimerRes(record, loc))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timerHandler) resumeTimer(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := &entity.TimerActionReq{Timezone: c.Query("timezone")}
+	if len(c.Body()) > 0 {
+		if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+			return utils.SendFailure(c, code, message, errors)
+		}
+	}
+
+	loc, err := h.service.Location(user, r.Timezone)
+	if err != nil {
+		return err
+	}
+
+	record, err := h.service.ResumeTimer(h.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "timer has been resumed", toTimerRes(record, loc))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timerHandler) currentTimer(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	loc, err := h.service.Location(user, c.Query("timezone"))
+	if err != nil {
+		return err
+	}
+
+	record, err := h.service.CurrentTimer(h.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toTimerRes(record, loc))
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type timesheetHandler struct {
+	ctx     context.Context
+	service *service.TimesheetService
+}
+
+func NewTimesheetHandler(service *service.TimesheetService) *timesheetHandler {
+	return &timesheetHandler{
+		ctx:     context.Background(),
+		service: service,
+	}
+}
+
+func toTimesheetRes(r *entity.Timesheet) entity.TimesheetRes {
+	return entity.TimesheetRes{
+		ID:          r.ID,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		UserID:      r.UserID,
+		Year:        r.Year,
+		Week:        r.Week,
+		Status:      r.Status,
+		SubmittedAt: r.SubmittedAt,
+		ReviewedAt:  r.ReviewedAt,
+		ReviewerID:  r.ReviewerID,
+		Comment:     r.Comment,
+	}
+}
+
+func (h *timesheetHandler) submitTimesheet(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := new(entity.TimesheetSubmitReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	record, err := h.service.SubmitTimesheet(h.ctx, user.ID, r.Year, r.Week)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "timesheet has been submitted", record)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timesheetHandler) getTimesheet(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	record, err := h.service.GetTimesheet(h.ctx, id, user)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", record)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timesheetHandler) listTimesheets(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	opts, err := utils.ParseQueryOptions(c)
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	timesheets, total, err := h.service.ListTimesheets(h.ctx, user, opts)
+	if err != nil {
+		return err
+	}
+
+	var res []entity.TimesheetRes
+	for _, p := range timesheets {
+		res = append(res, toTimesheetRes(&p))
+	}
+
+	response := utils.SuccessPageResponse(http.StatusOK, "data has been received", res, utils.NewPageMeta(opts, total))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timesheetHandler) approveTimesheet(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	r := new(entity.TimesheetReviewReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	record, err := h.service.ApproveTimesheet(h.ctx, id, user, r.Comment)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "timesheet has been approved", record)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *timesheetHandler) rejectTimesheet(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	r := new(entity.TimesheetReviewReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	record, err := h.service.RejectTimesheet(h.ctx, id, user, r.Comment)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "timesheet has been rejected", record)
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type twoFactorHandler struct {
+	ctx     context.Context
+	service *service.TwoFactorService
+}
+
+func NewTwoFactorHandler(service *service.TwoFactorService) *twoFactorHandler {
+	return &twoFactorHandler{
+		ctx:     context.Background(),
+		service: service,
+	}
+}
+
+func (h *twoFactorHandler) enroll(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	record, err := h.service.Enroll(h.ctx, user)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "scan the code with your authenticator app and confirm it", record)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *twoFactorHandler) confirm(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := new(entity.TwoFactorCodeReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	codes, err := h.service.Confirm(h.ctx, user, r.Code)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "two-factor authentication has been enabled, store the recovery codes somewhere safe", entity.RecoveryCodesRes{RecoveryCodes: codes})
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *twoFactorHandler) regenerateRecoveryCodes(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := new(entity.TwoFactorCodeReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(h.ctx, user, r.Code)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "recovery codes have been regenerated", entity.RecoveryCodesRes{RecoveryCodes: codes})
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *twoFactorHandler) disable(c *fiber.Ctx) error {
+	user, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	r := new(entity.TwoFactorCodeReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	if err := h.service.Disable(h.ctx, user, r.Code); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "two-factor authentication has been disabled", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
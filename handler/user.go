@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type userHandler struct {
+	ctx     context.Context
+	service *service.UserService
+}
+
+func NewUserHandler(service *service.UserService) *userHandler {
+	return &userHandler{
+		ctx:     context.Background(),
+		service: service,
+	}
+}
+
+func toStoreUser(r *entity.UserReq) *entity.User {
+	u := &entity.User{
+		Fullname: r.Fullname,
+		Email:    r.Email,
+		RoleID:   r.RoleID,
+		UploadID: r.UploadID,
+		Timezone: r.Timezone,
+		IsActive: true,
+	}
+
+	if r.Phone != "" {
+		u.Phone = &r.Phone
+	}
+
+	if r.IsActive != nil {
+		u.IsActive = *r.IsActive
+	}
+
+	if r.IsBlocked != nil {
+		u.IsBlocked = *r.IsBlocked
+	}
+
+	return u
+}
+
+func toAccountLockoutRes(r *entity.AccountLockout) entity.AccountLockoutRes {
+	return entity.AccountLockoutRes{
+		ID:               r.ID,
+		CreatedAt:        r.CreatedAt,
+		UserID:           r.UserID,
+		FailedLoginCount: r.FailedLoginCount,
+		LockedUntil:      r.LockedUntil,
+		UnlockedAt:       r.UnlockedAt,
+		UnlockedBy:       r.UnlockedBy,
+	}
+}
+
+func toUserRes(r *entity.User) entity.UserRes {
+	return entity.UserRes{
+		ID:          r.ID,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		DeletedAt:   r.DeletedAt,
+		Fullname:    r.Fullname,
+		Email:       r.Email,
+		Phone:       r.Phone,
+		IsActive:    r.IsActive,
+		IsBlocked:   r.IsBlocked,
+		RoleID:      r.RoleID,
+		UploadID:    r.UploadID,
+		Timezone:    r.Timezone,
+		LockedUntil: r.LockedUntil,
+	}
+}
+
+func pathUserReq(user *entity.User, r entity.UserReq) {
+	if r.Fullname != "" {
+		user.Fullname = r.Fullname
+	}
+
+	if r.Email != "" {
+		user.Email = r.Email
+	}
+
+	if r.Phone != "" {
+		user.Phone = &r.Phone
+	}
+
+	if r.IsActive != nil {
+		user.IsActive = *r.IsActive
+	}
+
+	if r.IsBlocked != nil {
+		user.IsBlocked = *r.IsBlocked
+	}
+
+	if r.RoleID != uuid.Nil {
+		user.RoleID = r.RoleID
+	}
+
+	if r.UploadID != nil {
+		user.UploadID = r.UploadID
+	}
+
+	if r.Timezone != "" {
+		user.Timezone = r.Timezone
+	}
+
+	user.UpdatedAt = toTimePtr(time.Now())
+}
+
+func (h *userHandler) createUser(c *fiber.Ctx) error {
+	r := new(entity.UserReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	if err := h.service.CheckRole(h.ctx, grantedPermissions(c), r.RoleID); err != nil {
+		return err
+	}
+
+	record, err := h.service.CreateUser(h.ctx, toStoreUser(r), r.Password)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been added", toUserRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *userHandler) getUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	record, err := h.service.GetUser(h.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", toUserRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *userHandler) listUsers(c *fiber.Ctx) error {
+	opts, err := utils.ParseQueryOptions(c)
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	users, total, err := h.service.ListUsers(h.ctx, c.QueryBool("with_deleted"), opts)
+	if err != nil {
+		return err
+	}
+
+	var res []entity.UserRes
+	for _, u := range users {
+		res = append(res, toUserRes(&u))
+	}
+
+	response := utils.SuccessPageResponse(http.StatusOK, "data has been received", res, utils.NewPageMeta(opts, total))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *userHandler) updateUser(c *fiber.Ctx) error {
+	// parsing uuid
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	// form validation
+	r := new(entity.UserReq)
+	if code, message, errors := utils.ParseFormDataAndValidate(c, r); errors != nil {
+		return utils.SendFailure(c, code, message, errors)
+	}
+
+	// get user by id
+	user, err := h.service.GetUser(h.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// only roles within the caller's own permissions can be handed out
+	if r.RoleID != uuid.Nil && r.RoleID != user.RoleID {
+		if err := h.service.CheckRole(h.ctx, grantedPermissions(c), r.RoleID); err != nil {
+			return err
+		}
+	}
+
+	// path update
+	pathUserReq(user, *r)
+	updated, err := h.service.UpdateUser(h.ctx, user, r.Password)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been updated", toUserRes(updated))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *userHandler) deleteUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.DeleteUser(h.ctx, id); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *userHandler) restoreUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	record, err := h.service.RestoreUser(h.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been restored", toUserRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *userHandler) forceDeleteUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	if err := h.service.ForceDeleteUser(h.ctx, id); err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been permanently deleted", nil)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *userHandler) unlockUser(c *fiber.Ctx) error {
+	admin, ok := authUser(c)
+	if !ok {
+		errFiber := fiber.NewError(http.StatusUnauthorized)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	record, err := h.service.UnlockUser(h.ctx, id, admin)
+	if err != nil {
+		return err
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "user has been unlocked", toUserRes(record))
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *userHandler) listAccountLockouts(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errFiber := fiber.NewError(http.StatusBadRequest)
+		return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{err.Error()})
+	}
+
+	records, err := h.service.ListAccountLockouts(h.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	res := []entity.AccountLockoutRes{}
+	for _, p := range records {
+		res = append(res, toAccountLockoutRes(&p))
+	}
+
+	response := utils.SuccessResponse(http.StatusOK, "data has been received", res)
+	return c.Status(http.StatusOK).JSON(response)
+}
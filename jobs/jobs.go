@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"gofi/config"
+	"gofi/database/repository"
+	"gofi/pkg/scheduler"
+	"gofi/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Jobs registers the recurring background jobs.
+func Jobs(db *sqlx.DB, s *scheduler.Scheduler) {
+	sessionRepo := repository.NewSessionRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	oidcRepo := repository.NewOIDCRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	cleanupService := service.NewCleanupService(sessionRepo, twoFactorRepo, oidcRepo, passwordResetRepo)
+
+	s.Register(scheduler.Job{
+		Name:     "purge-expired-sessions",
+		Interval: config.PurgeInterval(),
+		Run:      cleanupService.PurgeExpiredSessions,
+	})
+
+	s.Register(scheduler.Job{
+		Name:     "purge-expired-tokens",
+		Interval: config.PurgeInterval(),
+		Run:      cleanupService.PurgeExpiredTokens,
+	})
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"gofi/config"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/constant"
+	"gofi/pkg/utils"
+	"gofi/service"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuthConfig struct {
+	// Skip lists the paths that can be reached without a session,
+	// e.g. "/v1/auth/sign-in".
+	Skip []string
+
+	// TwoFactorSetup lists the paths still reachable by a user whose role
+	// requires two-factor authentication but who has not enabled it yet.
+	TwoFactorSetup []string
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func BearerToken(c *fiber.Ctx) string {
+	scheme, token, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+// Authorization resolves the bearer token to an unexpired session, or to a
+// personal API key when it carries the API key prefix, and loads the session
+// or key, its user, the user's role and the granted permissions into the
+// request locals. An API key is only granted the permissions of the role that
+// are also among its scopes. Users whose role requires two-factor
+// authentication are kept to the TwoFactorSetup paths until they enable it.
+func Authorization(db *sqlx.DB, cfg AuthConfig) fiber.Handler {
+	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	lockoutRepo := repository.NewAccountLockoutRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	authService := service.NewAuthService(userRepo, sessionRepo, permissionRepo, twoFactorRepo, lockoutRepo, service.AuthOptions{
+		SessionExpiresIn: config.SessionExpiresIn(),
+		RefreshExpiresIn: config.RefreshExpiresIn(),
+		KeySet:           config.JWTKeySet(),
+	})
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, permissionRepo)
+
+	return func(c *fiber.Ctx) error {
+		path := strings.TrimSuffix(c.Path(), "/")
+		if slices.Contains(cfg.Skip, path) {
+			return c.Next()
+		}
+
+		ctx := context.Background()
+
+		var (
+			session *entity.Session
+			apiKey  *entity.APIKey
+			user    *entity.User
+			err     error
+		)
+
+		token := BearerToken(c)
+		if strings.HasPrefix(token, constant.APIKeyPrefix) {
+			apiKey, user, err = apiKeyService.Authenticate(ctx, token)
+		} else {
+			session, user, err = authService.Authenticate(ctx, token)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		role, err := roleRepo.GetRole(ctx, user.RoleID)
+		if err != nil {
+			return err
+		}
+
+		permissions, err := permissionRepo.ListPermissionNamesByRole(ctx, role.ID)
+		if err != nil {
+			return err
+		}
+
+		if role.Require2FA && !slices.Contains(cfg.TwoFactorSetup, path) {
+			enabled, err := twoFactorRepo.IsTwoFactorEnabled(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+
+			if !enabled {
+				errFiber := fiber.NewError(http.StatusForbidden)
+				return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{service.ErrTwoFactorRequired.Error()})
+			}
+		}
+
+		if apiKey != nil {
+			scoped := make([]string, 0, len(apiKey.Scopes))
+			for _, permission := range permissions {
+				if slices.Contains(apiKey.Scopes, permission) {
+					scoped = append(scoped, permission)
+				}
+			}
+
+			permissions = scoped
+			c.Locals(constant.LocalsAPIKey, apiKey)
+		} else {
+			c.Locals(constant.LocalsSession, session)
+		}
+
+		c.Locals(constant.LocalsUser, user)
+		c.Locals(constant.LocalsRole, role)
+		c.Locals(constant.LocalsPermissions, permissions)
+
+		return c.Next()
+	}
+}
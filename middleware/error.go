@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"gofi/pkg/apperror"
+	"gofi/pkg/utils"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorHandler answers the errors returned by handlers with the
+// FailureResponse envelope. Domain errors get the status of their kind and
+// their message, fiber errors are passed through and anything else is a 500
+// whose details only go to the log.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var appErr *apperror.Error
+	var fiberErr *fiber.Error
+
+	code := http.StatusInternalServerError
+	var message string
+
+	switch {
+	case errors.As(err, &appErr):
+		code = apperror.Status(err)
+		message = appErr.Message
+	case errors.As(err, &fiberErr):
+		code = fiberErr.Code
+		message = fiberErr.Message
+	}
+
+	errFiber := fiber.NewError(code)
+	if code == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Method(), c.Path(), err)
+		message = errFiber.Message
+	}
+
+	return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{message})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"fmt"
+	"gofi/database/entity"
+	"gofi/pkg/constant"
+	"gofi/pkg/utils"
+	"net/http"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission only lets the request through when the authenticated
+// user's role holds every one of the given permissions. It must run after
+// Authorization, which loads the role's permissions into the locals.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		granted, ok := c.Locals(constant.LocalsPermissions).([]string)
+		if !ok {
+			errFiber := fiber.NewError(http.StatusUnauthorized)
+			return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"authentication required"})
+		}
+
+		for _, permission := range permissions {
+			if !slices.Contains(granted, permission) {
+				errFiber := fiber.NewError(http.StatusForbidden)
+				return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{fmt.Sprintf("missing permission %q", permission)})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireSession only lets the request through when it was authenticated with
+// a session, keeping personal API keys away from routes that manage the
+// account itself. It must run after Authorization.
+func RequireSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals(constant.LocalsSession).(*entity.Session); !ok {
+			errFiber := fiber.NewError(http.StatusForbidden)
+			return utils.SendFailure(c, int32(errFiber.Code), errFiber.Message, []string{"only available to a signed-in session, not to api keys"})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireScope only lets an API key through when it is scoped to every one of
+// the given permissions, requests authenticated with a session pass. It guards
+// routes open to every signed-in user. It must run after Authorization.
+func RequireScope(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals(constant.LocalsAPIKey).(*entity.APIKey); !ok {
+			return c.Next()
+		}
+
+		return RequirePermission(scopes...)(c)
+	}
+}
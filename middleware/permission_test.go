@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"gofi/database/entity"
+	"gofi/pkg/constant"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// withPrincipal stands in for Authorization, attaching either a session or
+// an API key holding the given permissions.
+func withPrincipal(session bool, permissions []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if session {
+			c.Locals(constant.LocalsSession, &entity.Session{})
+		} else {
+			c.Locals(constant.LocalsAPIKey, &entity.APIKey{Scopes: permissions})
+		}
+		c.Locals(constant.LocalsUser, &entity.User{})
+		c.Locals(constant.LocalsPermissions, permissions)
+
+		return c.Next()
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	tcs := []struct {
+		name    string
+		session bool
+		status  int
+	}{
+		{
+			name:    "session",
+			session: true,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "api key",
+			session: false,
+			status:  http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", withPrincipal(tc.session, nil), RequireSession(), func(c *fiber.Ctx) error {
+				return c.SendStatus(http.StatusOK)
+			})
+
+			res, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			require.NoError(t, err)
+			require.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tcs := []struct {
+		name        string
+		session     bool
+		permissions []string
+		status      int
+	}{
+		{
+			name:    "session without the permission",
+			session: true,
+			status:  http.StatusOK,
+		},
+		{
+			name:        "api key with the scope",
+			permissions: []string{constant.PermissionTimeEntryRead},
+			status:      http.StatusOK,
+		},
+		{
+			name:        "api key without the scope",
+			permissions: []string{constant.PermissionProjectWrite},
+			status:      http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", withPrincipal(tc.session, tc.permissions), RequireScope(constant.PermissionTimeEntryRead), func(c *fiber.Ctx) error {
+				return c.SendStatus(http.StatusOK)
+			})
+
+			res, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			require.NoError(t, err)
+			require.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Package apperror holds the domain errors shared by repositories, services
+// and handlers. Each error has a kind the central error handler turns into a
+// status code, and a message that is safe to show to clients.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kinds of domain errors, match them with errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrLocked       = errors.New("locked")
+)
+
+type Error struct {
+	Kind    error
+	Message string
+	// Err is the underlying cause, it is logged but never shown to clients.
+	Err error
+}
+
+// New returns a domain error of the kind, usually declared as a sentinel.
+func New(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap returns a domain error of the kind caused by err, which stays
+// reachable through errors.Is and errors.As.
+func Wrap(kind error, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Status is the HTTP status code of err, taken from the outermost domain
+// error so a wrapping error can change the kind of its cause. Any other
+// error is a 500.
+func Status(err error) int {
+	var e *Error
+	if !errors.As(err, &e) {
+		return http.StatusInternalServerError
+	}
+
+	switch e.Kind {
+	case ErrNotFound:
+		return http.StatusNotFound
+	case ErrConflict:
+		return http.StatusConflict
+	case ErrValidation:
+		return http.StatusUnprocessableEntity
+	case ErrForbidden:
+		return http.StatusForbidden
+	case ErrUnauthorized:
+		return http.StatusUnauthorized
+	case ErrLocked:
+		return http.StatusLocked
+	default:
+		return http.StatusInternalServerError
+	}
+}
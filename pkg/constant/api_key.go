@@ -0,0 +1,5 @@
+package constant
+
+// APIKeyPrefix starts every personal API key, so the auth middleware can
+// tell keys from session tokens.
+const APIKeyPrefix = "gofi_"
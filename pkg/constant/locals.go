@@ -0,0 +1,12 @@
+package constant
+
+// Keys of the values stored in fiber.Ctx.Locals for the current request.
+const (
+	LocalsUser        = "user"
+	LocalsRole        = "role"
+	LocalsSession     = "session"
+	LocalsPermissions = "permissions"
+)
+
+// LocalsAPIKey holds the API key the request was authenticated with, if any.
+const LocalsAPIKey = "api_key"
@@ -0,0 +1,36 @@
+package constant
+
+// Names of the permissions seeded by the permission migration.
+const (
+	PermissionRoleRead         = "role:read"
+	PermissionRoleManage       = "role:manage"
+	PermissionSessionRead      = "session:read"
+	PermissionSessionManage    = "session:manage"
+	PermissionProjectWrite     = "project:write"
+	PermissionTimesheetApprove = "timesheet:approve"
+)
+
+// Names of the permissions seeded by the user management migration.
+const (
+	PermissionUserManage = "user:manage"
+)
+
+// Names of the permissions seeded by the force delete migration.
+const (
+	PermissionRoleForceDelete    = "role:force-delete"
+	PermissionProjectForceDelete = "project:force-delete"
+	PermissionUserForceDelete    = "user:force-delete"
+)
+
+// Names of the permissions seeded by the time tracking migration. Sessions
+// are not checked for them, they scope what an API key can reach.
+const (
+	PermissionTimeEntryRead  = "time-entry:read"
+	PermissionTimeEntryWrite = "time-entry:write"
+)
+
+// Names of the permissions seeded by the project read migration. Sessions
+// are not checked for it, it scopes what an API key can reach.
+const (
+	PermissionProjectRead = "project:read"
+)
@@ -0,0 +1,8 @@
+package constant
+
+// Page sizes used when a list endpoint does not receive ?page_size= and the
+// most it accepts.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
@@ -0,0 +1,9 @@
+package constant
+
+// IDs of the roles seeded by the initial migration.
+const (
+	RoleSuperAdmin = "03ba326e-f9ed-410a-818f-eaa409c13622"
+	RoleAdmin      = "9dc8b32b-aefe-44d3-bf19-6dc088d13174"
+	RoleUser       = "d7efa7e9-3c97-4217-a6bd-59e2eba53068"
+	RoleGuest      = "be8482c9-7410-45eb-8c28-4dfd508a0de6"
+)
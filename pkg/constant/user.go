@@ -0,0 +1,4 @@
+package constant
+
+// DefaultTimezone matches the default of the "user"."timezone" column.
+const DefaultTimezone = "Asia/Jakarta"
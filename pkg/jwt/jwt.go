@@ -0,0 +1,168 @@
+// Package jwt issues and verifies the compact JWS tokens used as access
+// tokens, signed with EdDSA or RS256 keys identified by their kid.
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("token is malformed or its signature is invalid")
+	ErrExpiredToken = errors.New("token has expired")
+	ErrUnknownKey   = errors.New("token is signed with an unknown key")
+)
+
+// Claims carried by an access token.
+type Claims struct {
+	Issuer      string   `json:"iss"`
+	Subject     string   `json:"sub"`
+	IssuedAt    int64    `json:"iat"`
+	ExpiresAt   int64    `json:"exp"`
+	ID          string   `json:"jti"`
+	SessionID   string   `json:"sid"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+// KeySet holds every key tokens may be verified with, and the one new tokens
+// are signed with. Keeping the previous key in the set while signing with a
+// new one lets tokens issued before a rotation stay valid until they expire.
+type KeySet struct {
+	issuer  string
+	signing *Key
+	keys    map[string]*Key
+	order   []string
+}
+
+// NewKeySet builds a key set signing with the key whose ID is signingKid.
+func NewKeySet(issuer string, signingKid string, keys ...*Key) (*KeySet, error) {
+	ks := &KeySet{
+		issuer: issuer,
+		keys:   map[string]*Key{},
+	}
+
+	for _, k := range keys {
+		if _, ok := ks.keys[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %s", k.ID)
+		}
+
+		ks.keys[k.ID] = k
+		ks.order = append(ks.order, k.ID)
+	}
+
+	signing, ok := ks.keys[signingKid]
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not in the key set", signingKid)
+	}
+
+	ks.signing = signing
+
+	return ks, nil
+}
+
+// IsToken reports whether the value looks like a compact JWS rather than an
+// opaque token.
+func IsToken(value string) bool {
+	return strings.Count(value, ".") == 2
+}
+
+// Sign stamps the issuer on the claims and signs them with the signing key.
+func (ks *KeySet) Sign(claims Claims) (string, error) {
+	claims.Issuer = ks.issuer
+
+	h, err := json.Marshal(header{Algorithm: ks.signing.Algorithm, Type: "JWT", KeyID: ks.signing.ID})
+	if err != nil {
+		return "", err
+	}
+
+	p, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(p)
+
+	signature, err := ks.signing.sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks the signature, issuer and expiry of the token and returns its claims.
+func (ks *KeySet) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := ks.keys[h.KeyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	// never let the token pick the algorithm, it must match the key
+	if h.Algorithm != key.Algorithm {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Issuer != ks.issuer {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+// JWKS returns the public keys of the set as a JWKS document.
+func (ks *KeySet) JWKS() map[string][]JWK {
+	keys := []JWK{}
+	for _, kid := range ks.order {
+		keys = append(keys, ks.keys[kid].JWK())
+	}
+
+	return map[string][]JWK{"keys": keys}
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
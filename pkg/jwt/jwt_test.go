@@ -0,0 +1,255 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "gofi"
+
+func newEd25519Key(t *testing.T, kid string) *Key {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return parseTestKey(t, kid, private)
+}
+
+func newRSAKey(t *testing.T, kid string) *Key {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return parseTestKey(t, kid, private)
+}
+
+// parseTestKey goes through ParseKey, the way keys are loaded from disk.
+func parseTestKey(t *testing.T, kid string, private any) *Key {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+
+	key, err := ParseKey(kid, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	return key
+}
+
+func newKeySet(t *testing.T, signingKid string, keys ...*Key) *KeySet {
+	ks, err := NewKeySet(testIssuer, signingKid, keys...)
+	require.NoError(t, err)
+
+	return ks
+}
+
+// forge signs the header and claims as given, bypassing what Sign enforces.
+func forge(t *testing.T, key *Key, h header, claims Claims) string {
+	hb, err := json.Marshal(h)
+	require.NoError(t, err)
+
+	cb, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(hb) + "." + base64.RawURLEncoding.EncodeToString(cb)
+
+	signature, err := key.sign([]byte(signingInput))
+	require.NoError(t, err)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() Claims {
+	return Claims{
+		Subject:     "user",
+		IssuedAt:    time.Now().Unix(),
+		ExpiresAt:   time.Now().Add(time.Minute).Unix(),
+		ID:          "jti",
+		SessionID:   "session",
+		Role:        "Admin",
+		Permissions: []string{"role:read"},
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	tcs := []struct {
+		name      string
+		key       *Key
+		algorithm string
+	}{
+		{
+			name:      "EdDSA",
+			key:       newEd25519Key(t, "ed"),
+			algorithm: AlgorithmEdDSA,
+		},
+		{
+			name:      "RS256",
+			key:       newRSAKey(t, "rsa"),
+			algorithm: AlgorithmRS256,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.algorithm, tc.key.Algorithm)
+
+			ks := newKeySet(t, tc.key.ID, tc.key)
+
+			token, err := ks.Sign(validClaims())
+			require.NoError(t, err)
+			require.True(t, IsToken(token))
+
+			claims, err := ks.Verify(token)
+			require.NoError(t, err)
+			require.Equal(t, testIssuer, claims.Issuer)
+			require.Equal(t, "user", claims.Subject)
+			require.Equal(t, []string{"role:read"}, claims.Permissions)
+
+			var h header
+			require.NoError(t, decodeSegment(strings.Split(token, ".")[0], &h))
+			require.Equal(t, header{Algorithm: tc.algorithm, Type: "JWT", KeyID: tc.key.ID}, h)
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	previous := newEd25519Key(t, "key-1")
+	current := newEd25519Key(t, "key-2")
+
+	before := newKeySet(t, "key-1", previous)
+	after := newKeySet(t, "key-2", previous, current)
+
+	oldToken, err := before.Sign(validClaims())
+	require.NoError(t, err)
+
+	newToken, err := after.Sign(validClaims())
+	require.NoError(t, err)
+
+	t.Run("should sign with the new key", func(t *testing.T) {
+		var h header
+		require.NoError(t, decodeSegment(strings.Split(newToken, ".")[0], &h))
+		require.Equal(t, "key-2", h.KeyID)
+	})
+
+	t.Run("should still verify tokens of the previous key", func(t *testing.T) {
+		_, err := after.Verify(oldToken)
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject tokens once the previous key is dropped", func(t *testing.T) {
+		_, err := newKeySet(t, "key-2", current).Verify(oldToken)
+		require.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("should publish both keys", func(t *testing.T) {
+		keys := after.JWKS()["keys"]
+		require.Len(t, keys, 2)
+		require.Equal(t, "key-1", keys[0].KeyID)
+		require.Equal(t, "key-2", keys[1].KeyID)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	key := newEd25519Key(t, "key-1")
+	ks := newKeySet(t, "key-1", key)
+
+	tcs := []struct {
+		name  string
+		token func() string
+		err   error
+	}{
+		{
+			name: "algorithm not matching the key",
+			token: func() string {
+				return forge(t, key, header{Algorithm: AlgorithmRS256, Type: "JWT", KeyID: "key-1"}, validClaims())
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "unsigned",
+			token: func() string {
+				parts := strings.Split(forge(t, key, header{Algorithm: "none", Type: "JWT", KeyID: "key-1"}, validClaims()), ".")
+				return parts[0] + "." + parts[1] + "."
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := validClaims()
+				claims.Issuer = testIssuer
+				claims.ExpiresAt = time.Now().Add(-time.Second).Unix()
+				return forge(t, key, header{Algorithm: AlgorithmEdDSA, Type: "JWT", KeyID: "key-1"}, claims)
+			},
+			err: ErrExpiredToken,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				claims := validClaims()
+				claims.Issuer = "another-app"
+				return forge(t, key, header{Algorithm: AlgorithmEdDSA, Type: "JWT", KeyID: "key-1"}, claims)
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "tampered claims",
+			token: func() string {
+				original, err := ks.Sign(validClaims())
+				require.NoError(t, err)
+
+				claims := validClaims()
+				claims.Permissions = []string{"role:delete"}
+				forged, err := ks.Sign(claims)
+				require.NoError(t, err)
+
+				o, f := strings.Split(original, "."), strings.Split(forged, ".")
+				return f[0] + "." + f[1] + "." + o[2]
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "unknown key",
+			token: func() string {
+				token, err := newKeySet(t, "key-9", newEd25519Key(t, "key-9")).Sign(validClaims())
+				require.NoError(t, err)
+				return token
+			},
+			err: ErrUnknownKey,
+		},
+		{
+			name: "malformed",
+			token: func() string {
+				return "not-a-token"
+			},
+			err: ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ks.Verify(tc.token())
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestNewKeySet(t *testing.T) {
+	key := newEd25519Key(t, "key-1")
+
+	t.Run("should reject duplicate key ids", func(t *testing.T) {
+		_, err := NewKeySet(testIssuer, "key-1", key, newEd25519Key(t, "key-1"))
+		require.ErrorContains(t, err, "duplicate key id")
+	})
+
+	t.Run("should reject a signing key outside the set", func(t *testing.T) {
+		_, err := NewKeySet(testIssuer, "key-2", key)
+		require.ErrorContains(t, err, "not in the key set")
+	})
+}
@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+// Key is a signing key identified by its kid. The algorithm follows from the
+// key type: Ed25519 keys sign with EdDSA and RSA keys with RS256.
+type Key struct {
+	ID        string
+	Algorithm string
+	signer    crypto.Signer
+}
+
+// ParseKey reads a PEM encoded PKCS#8 (or PKCS#1 RSA) private key.
+func ParseKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", kid)
+	}
+
+	var (
+		parsed any
+		err    error
+	)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		return &Key{ID: kid, Algorithm: AlgorithmEdDSA, signer: k}, nil
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("key %s: RSA keys must be at least 2048 bits", kid)
+		}
+
+		return &Key{ID: kid, Algorithm: AlgorithmRS256, signer: k}, nil
+	default:
+		return nil, fmt.Errorf("key %s: unsupported key type %T", kid, parsed)
+	}
+}
+
+// LoadKey reads the key from a PEM file.
+func LoadKey(kid string, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	return ParseKey(kid, data)
+}
+
+// JWK is the public part of a key as published in a JWKS document.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// JWK returns the public key in JWK form.
+func (k *Key) JWK() JWK {
+	jwk := JWK{
+		KeyID:     k.ID,
+		Use:       "sig",
+		Algorithm: k.Algorithm,
+	}
+
+	switch pub := k.signer.Public().(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	}
+
+	return jwk
+}
+
+func (k *Key) sign(signingInput []byte) ([]byte, error) {
+	switch k.Algorithm {
+	case AlgorithmEdDSA:
+		return k.signer.Sign(nil, signingInput, crypto.Hash(0))
+	case AlgorithmRS256:
+		digest := crypto.SHA256.New()
+		digest.Write(signingInput)
+		return k.signer.Sign(nil, digest.Sum(nil), crypto.SHA256)
+	default:
+		return nil, errors.New("unsupported algorithm " + k.Algorithm)
+	}
+}
+
+func (k *Key) verify(signingInput []byte, signature []byte) bool {
+	switch pub := k.signer.Public().(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, signingInput, signature)
+	case *rsa.PublicKey:
+		digest := crypto.SHA256.New()
+		digest.Write(signingInput)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest.Sum(nil), signature) == nil
+	default:
+		return false
+	}
+}
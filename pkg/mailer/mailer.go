@@ -0,0 +1,24 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// Mailer delivers plain text emails.
+type Mailer interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+type logMailer struct{}
+
+// NewLogMailer returns a Mailer that writes every email to the application
+// log instead of sending it, which is enough for local development.
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	log.Printf("mail to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
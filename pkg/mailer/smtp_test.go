@@ -0,0 +1,125 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// delivery is what the stand-in server received.
+type delivery struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is a stand-in SMTP server accepting a single connection. When
+// silent it accepts the connection but never greets, like a hung server.
+func smtpServer(t *testing.T, silent bool) (SMTPConfig, <-chan delivery) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	deliveries := make(chan delivery, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if silent {
+			// hold the connection until the test is done
+			buf := make([]byte, 1)
+			conn.Read(buf)
+			return
+		}
+
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ESMTP stand-in")
+
+		var d delivery
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 localhost")
+			case "MAIL":
+				d.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				d.to = append(d.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				d.data = string(data)
+				tp.PrintfLine("250 OK")
+				deliveries <- d
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	return SMTPConfig{Host: host, Port: port, From: "no-reply@example.com"}, deliveries
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	t.Run("should deliver the message", func(t *testing.T) {
+		cfg, deliveries := smtpServer(t, false)
+
+		err := NewSMTPMailer(cfg).Send(context.Background(), "user@example.com", "Reset your password", "Hi,\nopen the link.")
+		require.NoError(t, err)
+
+		d := <-deliveries
+		require.Equal(t, "no-reply@example.com", d.from)
+		require.Equal(t, []string{"user@example.com"}, d.to)
+		require.Contains(t, d.data, "To: user@example.com\n")
+		require.Contains(t, d.data, "Subject: Reset your password\n")
+		require.Contains(t, d.data, "Content-Type: text/plain; charset=UTF-8\n")
+		require.True(t, strings.HasSuffix(d.data, "\nHi,\nopen the link.\n"))
+	})
+
+	t.Run("should give up on a server that does not answer", func(t *testing.T) {
+		cfg, _ := smtpServer(t, true)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := NewSMTPMailer(cfg).Send(ctx, "user@example.com", "Reset your password", "Hi")
+		require.Error(t, err)
+		require.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("should fail when the server is unreachable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		host, port, _ := net.SplitHostPort(listener.Addr().String())
+		listener.Close()
+
+		err = NewSMTPMailer(SMTPConfig{Host: host, Port: port}).Send(context.Background(), "user@example.com", "Subject", "Hi")
+		require.ErrorContains(t, err, "error connecting to smtp server")
+	})
+}
@@ -0,0 +1,123 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// jwk is a public key as published in the provider's JWKS document.
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// publicKey is a provider key together with the only algorithm it may verify.
+type publicKey struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// publicKey converts the JWK into an RS256, ES256 or EdDSA verification key.
+func (k jwk) publicKey() (*publicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		if n.BitLen() < 2048 || !e.IsInt64() {
+			return nil, fmt.Errorf("key %s: unsupported RSA key", k.KeyID)
+		}
+
+		return &publicKey{algorithm: "RS256", key: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("key %s: unsupported curve %s", k.KeyID, k.Curve)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("key %s: point is not on the curve", k.KeyID)
+		}
+
+		return &publicKey{algorithm: "ES256", key: &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("key %s: unsupported curve %s", k.KeyID, k.Curve)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("key %s: invalid Ed25519 key", k.KeyID)
+		}
+
+		return &publicKey{algorithm: "EdDSA", key: ed25519.PublicKey(x)}, nil
+	default:
+		return nil, fmt.Errorf("key %s: unsupported key type %s", k.KeyID, k.KeyType)
+	}
+}
+
+func (k *publicKey) verify(signingInput []byte, signature []byte) bool {
+	switch pub := k.key.(type) {
+	case *rsa.PublicKey:
+		digest := crypto.SHA256.New()
+		digest.Write(signingInput)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest.Sum(nil), signature) == nil
+	case *ecdsa.PublicKey:
+		// JWS carries ES256 signatures as the raw 32 byte r and s values
+		if len(signature) != 64 {
+			return false
+		}
+
+		digest := crypto.SHA256.New()
+		digest.Write(signingInput)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, digest.Sum(nil), r, s)
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, signingInput, signature)
+	default:
+		return false
+	}
+}
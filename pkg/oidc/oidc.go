@@ -0,0 +1,399 @@
+// Package oidc is a small OpenID Connect relying party: it discovers the
+// provider, builds authorization code + PKCE requests, exchanges the code and
+// verifies the returned ID token against the provider's published keys.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("id token is malformed, expired or not issued for this client")
+	ErrUnknownKey     = errors.New("id token is signed with an unknown key")
+)
+
+type Config struct {
+	// Issuer is the provider URL, e.g. "https://idp.example.com/realms/staff".
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Metadata is the part of the discovery document the relying party needs.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Token is the token endpoint response.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Claims of an ID token.
+type Claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	ExpiresAt     int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Name          string   `json:"name"`
+}
+
+// audience accepts both forms of the aud claim, a string or a list.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*a = list
+	return nil
+}
+
+// keysRefreshInterval limits how often an unknown kid triggers a JWKS fetch.
+const keysRefreshInterval = time.Minute
+
+// Provider talks to one OpenID provider. Discovery happens on first use, so
+// the API can start while the provider is unreachable.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *Metadata
+	keys          map[string]*publicKey
+	keysFetchedAt time.Time
+}
+
+func NewProvider(cfg Config) *Provider {
+	return &Provider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Issuer returns the configured issuer URL.
+func (p *Provider) Issuer() string {
+	return p.cfg.Issuer
+}
+
+// GenerateVerifier returns a random PKCE code verifier.
+func GenerateVerifier() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// Challenge derives the S256 PKCE code challenge from the verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", endpoint, res.Status)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// discover fetches and caches the discovery document. The fetch happens
+// outside the lock so a slow provider does not hold up other requests.
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	cached := p.metadata
+	p.mu.Unlock()
+
+	if cached != nil {
+		return cached, nil
+	}
+
+	var metadata Metadata
+	endpoint := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, endpoint, &metadata); err != nil {
+		return nil, fmt.Errorf("error discovering oidc provider: %w", err)
+	}
+
+	// the document must describe the issuer it was fetched from
+	if metadata.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("error discovering oidc provider: issuer %q does not match %q", metadata.Issuer, p.cfg.Issuer)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// a concurrent discovery may have won the race, keep its document
+	if p.metadata == nil {
+		p.metadata = &metadata
+	}
+
+	return p.metadata, nil
+}
+
+// AuthCodeURL returns the authorization endpoint URL the user is sent to.
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return metadata.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange trades the authorization code for tokens.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (*Token, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging authorization code: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("error exchanging authorization code: %s: %s", res.Status, body)
+	}
+
+	var token Token
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("error exchanging authorization code: %w", err)
+	}
+
+	if token.IDToken == "" {
+		return nil, errors.New("error exchanging authorization code: no id_token in response")
+	}
+
+	return &token, nil
+}
+
+// key returns the provider key with the given kid, refetching the JWKS when
+// the kid is unknown so provider key rotations are picked up. Like discovery,
+// the fetch happens outside the lock.
+func (p *Provider) key(ctx context.Context, kid string) (*publicKey, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+
+	if key, ok := p.keys[kid]; ok {
+		p.mu.Unlock()
+		return key, nil
+	}
+
+	if time.Since(p.keysFetchedAt) < keysRefreshInterval {
+		p.mu.Unlock()
+		return nil, ErrUnknownKey
+	}
+
+	// claim the refresh so concurrent lookups of unknown kids do not all fetch
+	lastFetchedAt := p.keysFetchedAt
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	keys, err := p.fetchKeys(ctx, metadata.JWKSURI)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		// a failed fetch does not count against the refresh interval
+		p.keysFetchedAt = lastFetchedAt
+		return nil, err
+	}
+
+	p.keys = keys
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// fetchKeys downloads the JWKS and keeps the keys usable for signatures.
+func (p *Provider) fetchKeys(ctx context.Context, jwksURI string) (map[string]*publicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("error fetching oidc keys: %w", err)
+	}
+
+	keys := map[string]*publicKey{}
+	for _, k := range set.Keys {
+		// keys for encryption or of unsupported types are skipped
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+
+		keys[k.KeyID] = key
+	}
+
+	return keys, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of
+// the ID token and returns its claims.
+func (p *Provider) VerifyIDToken(ctx context.Context, raw string, nonce string) (*Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidIDToken
+	}
+
+	var h struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	key, err := p.key(ctx, h.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// never let the token pick the algorithm, it must match the key
+	if h.Algorithm != key.algorithm {
+		return nil, ErrInvalidIDToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	if !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidIDToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	if claims.Issuer != p.cfg.Issuer || claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	found := false
+	for _, aud := range claims.Audience {
+		if aud == p.cfg.ClientID {
+			found = true
+		}
+	}
+	if !found {
+		return nil, ErrInvalidIDToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidIDToken
+	}
+
+	if claims.Nonce != nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	return &claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,249 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClientID     = "gofi"
+	testClientSecret = "secret"
+	testKeyID        = "key-1"
+)
+
+// issuer is a minimal OpenID provider serving discovery, JWKS and the token
+// endpoint.
+type issuer struct {
+	*httptest.Server
+	private ed25519.PrivateKey
+	idToken string
+}
+
+func newIssuer(t *testing.T) *issuer {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	i := &issuer{private: private}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{
+			Issuer:                i.URL,
+			AuthorizationEndpoint: i.URL + "/authorize",
+			TokenEndpoint:         i.URL + "/token",
+			JWKSURI:               i.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]jwk{
+			"keys": {{KeyType: "OKP", KeyID: testKeyID, Use: "sig", Curve: "Ed25519", X: base64.RawURLEncoding.EncodeToString(public)}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		if clientID != testClientID || clientSecret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("code") != "code" || r.PostFormValue("code_verifier") != "verifier" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		json.NewEncoder(w).Encode(Token{AccessToken: "access", TokenType: "Bearer", IDToken: i.idToken, ExpiresIn: 300})
+	})
+
+	i.Server = httptest.NewServer(mux)
+	t.Cleanup(i.Close)
+
+	return i
+}
+
+func (i *issuer) provider() *Provider {
+	return NewProvider(Config{
+		Issuer:       i.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  "http://localhost/callback",
+		Scopes:       []string{"openid", "email"},
+	})
+}
+
+// sign builds an ID token with the given header and claims.
+func (i *issuer) sign(t *testing.T, header map[string]string, claims map[string]any) string {
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	signature := ed25519.Sign(i.private, []byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (i *issuer) claims() map[string]any {
+	return map[string]any{
+		"iss":   i.URL,
+		"sub":   "subject",
+		"aud":   testClientID,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": "nonce",
+		"email": "user@example.com",
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	i := newIssuer(t)
+
+	t.Run("should return the provider endpoints", func(t *testing.T) {
+		metadata, err := i.provider().discover(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, i.URL+"/token", metadata.TokenEndpoint)
+		require.Equal(t, i.URL+"/jwks", metadata.JWKSURI)
+	})
+
+	t.Run("should build the authorization url with pkce", func(t *testing.T) {
+		authURL, err := i.provider().AuthCodeURL(context.Background(), "state", "nonce", Challenge("verifier"))
+		require.NoError(t, err)
+		require.Contains(t, authURL, i.URL+"/authorize?")
+		require.Contains(t, authURL, "code_challenge_method=S256")
+		require.Contains(t, authURL, "state=state")
+	})
+
+	t.Run("should reject a document of another issuer", func(t *testing.T) {
+		p := NewProvider(Config{Issuer: i.URL + "/"})
+
+		_, err := p.discover(context.Background())
+		require.ErrorContains(t, err, "does not match")
+	})
+}
+
+func TestExchange(t *testing.T) {
+	i := newIssuer(t)
+	i.idToken = i.sign(t, map[string]string{"alg": "EdDSA", "kid": testKeyID}, i.claims())
+
+	t.Run("should exchange the code for tokens", func(t *testing.T) {
+		token, err := i.provider().Exchange(context.Background(), "code", "verifier")
+		require.NoError(t, err)
+		require.Equal(t, "access", token.AccessToken)
+		require.Equal(t, i.idToken, token.IDToken)
+	})
+
+	t.Run("should fail on a rejected code", func(t *testing.T) {
+		_, err := i.provider().Exchange(context.Background(), "code", "other verifier")
+		require.ErrorContains(t, err, "invalid_grant")
+	})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	i := newIssuer(t)
+	header := map[string]string{"alg": "EdDSA", "kid": testKeyID}
+
+	tcs := []struct {
+		name  string
+		token func(claims map[string]any) string
+		err   error
+	}{
+		{
+			name: "valid token",
+			token: func(claims map[string]any) string {
+				return i.sign(t, header, claims)
+			},
+		},
+		{
+			name: "wrong issuer",
+			token: func(claims map[string]any) string {
+				claims["iss"] = "https://attacker.example.com"
+				return i.sign(t, header, claims)
+			},
+			err: ErrInvalidIDToken,
+		},
+		{
+			name: "wrong audience",
+			token: func(claims map[string]any) string {
+				claims["aud"] = []string{"another-client"}
+				return i.sign(t, header, claims)
+			},
+			err: ErrInvalidIDToken,
+		},
+		{
+			name: "expired",
+			token: func(claims map[string]any) string {
+				claims["exp"] = time.Now().Add(-time.Second).Unix()
+				return i.sign(t, header, claims)
+			},
+			err: ErrInvalidIDToken,
+		},
+		{
+			name: "wrong nonce",
+			token: func(claims map[string]any) string {
+				claims["nonce"] = "replayed"
+				return i.sign(t, header, claims)
+			},
+			err: ErrInvalidIDToken,
+		},
+		{
+			name: "algorithm not matching the key",
+			token: func(claims map[string]any) string {
+				return i.sign(t, map[string]string{"alg": "HS256", "kid": testKeyID}, claims)
+			},
+			err: ErrInvalidIDToken,
+		},
+		{
+			name: "unsigned",
+			token: func(claims map[string]any) string {
+				parts := strings.Split(i.sign(t, map[string]string{"alg": "none", "kid": testKeyID}, claims), ".")
+				return parts[0] + "." + parts[1] + "."
+			},
+			err: ErrInvalidIDToken,
+		},
+		{
+			name: "tampered claims",
+			token: func(claims map[string]any) string {
+				original := strings.Split(i.sign(t, header, claims), ".")
+				claims["sub"] = "someone else"
+				forged := strings.Split(i.sign(t, header, claims), ".")
+				return forged[0] + "." + forged[1] + "." + original[2]
+			},
+			err: ErrInvalidIDToken,
+		},
+		{
+			name: "unknown key",
+			token: func(claims map[string]any) string {
+				return i.sign(t, map[string]string{"alg": "EdDSA", "kid": "key-2"}, claims)
+			},
+			err: ErrUnknownKey,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			p := i.provider()
+
+			claims, err := p.VerifyIDToken(context.Background(), tc.token(i.claims()), "nonce")
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "subject", claims.Subject)
+			require.Equal(t, "user@example.com", claims.Email)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Package scheduler runs recurring jobs inside the API process. When several
+// replicas are deployed they elect a leader through a session level Postgres
+// advisory lock, only the replica holding it runs the jobs. The lock lives on
+// a dedicated connection for as long as the leader does, if that connection
+// is lost another replica takes over.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Job is a recurring task. Run receives a context that is cancelled when the
+// scheduler stops.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// electionInterval is how often a follower retries to become the leader and
+// how often the leader checks it still holds the lock.
+const electionInterval = 15 * time.Second
+
+type Scheduler struct {
+	db   *sqlx.DB
+	jobs []Job
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(db *sqlx.DB) *Scheduler {
+	return &Scheduler{
+		db: db,
+	}
+}
+
+// Register adds a job, it must be called before Start.
+func (s *Scheduler) Register(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start campaigns for leadership in the background. Once elected, every
+// registered job runs once right away and then on its interval.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.elect(ctx)
+
+	log.Printf("scheduler started with %d jobs", len(s.jobs))
+}
+
+// Stop cancels running jobs, waits for them to return and gives up the
+// leadership.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	s.wg.Wait()
+
+	log.Printf("scheduler stopped")
+}
+
+// elect tries to become the leader every electionInterval and leads until
+// the scheduler stops or the lock is lost.
+func (s *Scheduler) elect(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.acquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("scheduler: %v", err)
+		}
+
+		if conn != nil {
+			log.Printf("scheduler: elected leader")
+			s.lead(ctx, conn)
+			s.release(conn)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(electionInterval):
+		}
+	}
+}
+
+// acquire returns a connection holding the leader lock, or nil when another
+// replica leads.
+func (s *Scheduler) acquire(ctx context.Context) (*sqlx.Conn, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error acquiring leader lock: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, leaderKey).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error acquiring leader lock: %w", err)
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil, nil
+	}
+
+	return conn, nil
+}
+
+// lead runs the jobs until ctx is cancelled or the connection holding the
+// lock stops answering, Postgres drops the lock together with the session.
+func (s *Scheduler) lead(ctx context.Context, conn *sqlx.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+
+	ticker := time.NewTicker(electionInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("scheduler: lost leader lock: %v", err)
+				cancel()
+			}
+		}
+	}
+
+	wg.Wait()
+}
+
+// release unlocks and hands the connection back to the pool, the lock must
+// not stay behind on a pooled connection.
+func (s *Scheduler) release(conn *sqlx.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, leaderKey); err != nil {
+		log.Printf("scheduler: error releasing leader lock: %v", err)
+	}
+
+	conn.Close()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("job %s: %v", job.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// leaderKey is the advisory lock key held by the leading replica.
+var leaderKey = lockKey("scheduler:leader")
+
+// lockKey derives an advisory lock key from a name.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
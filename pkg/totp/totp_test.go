@@ -0,0 +1,134 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// secret of the RFC 6238 SHA-1 test vectors, "12345678901234567890" in base32
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to the 6 digits authenticator apps use
+	tcs := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(time.Unix(tc.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(tc.unix, 0)))
+			require.NoError(t, err)
+			require.Equal(t, tc.code, code)
+		})
+	}
+
+	t.Run("should accept lower case secrets", func(t *testing.T) {
+		code, err := Code(strings.ToLower(rfcSecret), Step(time.Unix(59, 0)))
+		require.NoError(t, err)
+		require.Equal(t, "287082", code)
+	})
+
+	t.Run("should reject an invalid secret", func(t *testing.T) {
+		_, err := Code("not base32!", 1)
+		require.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		require.NoError(t, err)
+		return code
+	}
+
+	tcs := []struct {
+		name string
+		code string
+		ok   bool
+		step int64
+	}{
+		{
+			name: "current step",
+			code: codeAt(current),
+			ok:   true,
+			step: current,
+		},
+		{
+			name: "previous step within the skew",
+			code: codeAt(current - 1),
+			ok:   true,
+			step: current - 1,
+		},
+		{
+			name: "next step within the skew",
+			code: codeAt(current + 1),
+			ok:   true,
+			step: current + 1,
+		},
+		{
+			name: "two steps behind",
+			code: codeAt(current - 2),
+		},
+		{
+			name: "two steps ahead",
+			code: codeAt(current + 2),
+		},
+		{
+			name: "surrounding whitespace",
+			code: " " + codeAt(current) + "\n",
+			ok:   true,
+			step: current,
+		},
+		{
+			name: "wrong length",
+			code: codeAt(current)[:5],
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tc.code, now)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.step, step)
+		})
+	}
+
+	t.Run("should report the original step of a replayed code", func(t *testing.T) {
+		code := codeAt(current)
+
+		first, ok := Validate(rfcSecret, code, now)
+		require.True(t, ok)
+
+		// one step later the code is still inside the skew, callers refuse
+		// it because its step is not after the last one used
+		replayed, ok := Validate(rfcSecret, code, now.Add(period*time.Second))
+		require.True(t, ok)
+		require.Equal(t, first, replayed)
+		require.Less(t, replayed, Step(now.Add(period*time.Second)))
+	})
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("gofi", "user@example.com", rfcSecret)
+
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/gofi:user@example.com?"))
+	require.Contains(t, uri, "secret="+rfcSecret)
+	require.Contains(t, uri, "issuer=gofi")
+	require.Contains(t, uri, "digits=6")
+	require.Contains(t, uri, "period=30")
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"gofi/database/entity"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func signCursor(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// EncodeCursor turns the cursor into an opaque string signed with the secret,
+// so clients cannot craft their own.
+func EncodeCursor(secret []byte, cursor entity.Cursor) string {
+	b, _ := json.Marshal(cursor)
+	payload := base64.RawURLEncoding.EncodeToString(b)
+
+	return payload + "." + signCursor(secret, payload)
+}
+
+// DecodeCursor reads a cursor made by EncodeCursor with the same secret.
+func DecodeCursor(secret []byte, value string) (*entity.Cursor, error) {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signCursor(secret, payload))) {
+		return nil, ErrInvalidCursor
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor entity.Cursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// ParseCursor switches opts to keyset pagination when the request has a
+// ?cursor= parameter. An empty cursor asks for the first page.
+func ParseCursor(c *fiber.Ctx, secret []byte, opts *entity.QueryOptions) error {
+	args := c.Context().QueryArgs()
+	if !args.Has("cursor") {
+		return nil
+	}
+
+	if args.Has("page") {
+		return errors.New("page and cursor cannot be used together")
+	}
+
+	opts.Keyset = true
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := DecodeCursor(secret, value)
+		if err != nil {
+			return err
+		}
+		opts.Cursor = cursor
+	}
+
+	return nil
+}
+
+// cursorLink is the URL of the request with its cursor replaced.
+func cursorLink(c *fiber.Ctx, secret []byte, cursor *entity.Cursor) *string {
+	if cursor == nil {
+		return nil
+	}
+
+	query := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		query.Add(string(key), string(value))
+	})
+	query.Set("cursor", EncodeCursor(secret, *cursor))
+
+	link := c.BaseURL() + c.Path() + "?" + query.Encode()
+	return &link
+}
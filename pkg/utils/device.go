@@ -0,0 +1,58 @@
+package utils
+
+import "strings"
+
+// userAgentMatch maps a user agent fragment to a readable name. Order matters,
+// e.g. Edge and Chrome user agents both mention Chrome and Safari.
+type userAgentMatch struct {
+	fragment string
+	name     string
+}
+
+var browsers = []userAgentMatch{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+	{"PostmanRuntime/", "Postman"},
+	{"okhttp/", "OkHttp"},
+	{"Go-http-client/", "Go"},
+}
+
+var platforms = []userAgentMatch{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+func matchUserAgent(userAgent string, matches []userAgentMatch) string {
+	for _, m := range matches {
+		if strings.Contains(userAgent, m.fragment) {
+			return m.name
+		}
+	}
+
+	return ""
+}
+
+// DeviceName returns a short description of the client, e.g. "Chrome on
+// macOS", good enough to tell sessions apart. Unknown clients yield "".
+func DeviceName(userAgent string) string {
+	browser := matchUserAgent(userAgent, browsers)
+	platform := matchUserAgent(userAgent, platforms)
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	default:
+		return platform
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEApplicationProblemJSON is the media type of RFC 7807 problem documents.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 problem document, Errors lists the fields a
+// validation failure is about.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int32        `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem describes a failure built for FailureResponse as a problem
+// document, instance is the id of the request.
+func NewProblem(code int32, message string, errors interface{}, instance string) Problem {
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    message,
+		Status:   code,
+		Instance: instance,
+	}
+
+	var details []string
+	switch errs := errors.(type) {
+	case []string:
+		details = errs
+	case []FieldError:
+		for _, e := range errs {
+			if e.Field == "" {
+				details = append(details, e.Message)
+				continue
+			}
+			problem.Errors = append(problem.Errors, e)
+		}
+	case nil:
+	default:
+		details = []string{fmt.Sprint(errs)}
+	}
+	problem.Detail = strings.Join(details, "; ")
+
+	return problem
+}
+
+// SendFailure answers the request with a failure, as a problem document when
+// the client accepts application/problem+json and in the FailureResponse
+// envelope otherwise.
+func SendFailure(c *fiber.Ctx, code int32, message string, errors interface{}) error {
+	c.Vary(fiber.HeaderAccept)
+
+	if c.Accepts(fiber.MIMEApplicationJSON, MIMEApplicationProblemJSON) == MIMEApplicationProblemJSON {
+		problem := NewProblem(code, message, errors, c.GetRespHeader(fiber.HeaderXRequestID))
+		return c.Status(int(code)).JSON(problem, MIMEApplicationProblemJSON)
+	}
+
+	return c.Status(int(code)).JSON(FailureResponse(code, message, errors))
+}
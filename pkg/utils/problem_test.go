@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProblem(t *testing.T) {
+	tcs := []struct {
+		name   string
+		errors interface{}
+		detail string
+		fields []FieldError
+	}{
+		{
+			name:   "messages become the detail",
+			errors: []string{"name is taken", "try another one"},
+			detail: "name is taken; try another one",
+		},
+		{
+			name: "field errors stay apart from the detail",
+			errors: []FieldError{
+				{Message: "Unprocessable Entity"},
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+			},
+			detail: "Unprocessable Entity",
+			fields: []FieldError{
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+			},
+		},
+		{
+			name: "no errors",
+		},
+		{
+			name:   "anything else is printed",
+			errors: 42,
+			detail: "42",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			problem := NewProblem(http.StatusBadRequest, "Bad Request", tc.errors, "request-id")
+
+			require.Equal(t, "about:blank", problem.Type)
+			require.Equal(t, "Bad Request", problem.Title)
+			require.Equal(t, int32(http.StatusBadRequest), problem.Status)
+			require.Equal(t, "request-id", problem.Instance)
+			require.Equal(t, tc.detail, problem.Detail)
+			require.Equal(t, tc.fields, problem.Errors)
+		})
+	}
+}
+
+func TestSendFailure(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderXRequestID, "request-id")
+		return SendFailure(c, http.StatusBadRequest, "Bad Request", []FieldError{
+			{Field: "name", Rule: "required", Message: "name is a required field"},
+		})
+	})
+
+	tcs := []struct {
+		name        string
+		accept      string
+		contentType string
+		problem     bool
+	}{
+		{
+			name:        "no accept header",
+			contentType: fiber.MIMEApplicationJSON,
+		},
+		{
+			name:        "json",
+			accept:      fiber.MIMEApplicationJSON,
+			contentType: fiber.MIMEApplicationJSON,
+		},
+		{
+			name:        "problem json",
+			accept:      MIMEApplicationProblemJSON,
+			contentType: MIMEApplicationProblemJSON,
+			problem:     true,
+		},
+		{
+			name:        "problem json preferred",
+			accept:      "application/json;q=0.5, application/problem+json",
+			contentType: MIMEApplicationProblemJSON,
+			problem:     true,
+		},
+		{
+			name:        "json preferred",
+			accept:      "application/json, application/problem+json;q=0.5",
+			contentType: fiber.MIMEApplicationJSON,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				req.Header.Set(fiber.HeaderAccept, tc.accept)
+			}
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			require.Equal(t, http.StatusBadRequest, res.StatusCode)
+			require.Equal(t, fiber.HeaderAccept, res.Header.Get(fiber.HeaderVary))
+			require.Contains(t, res.Header.Get(fiber.HeaderContentType), tc.contentType)
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+
+			if tc.problem {
+				require.Equal(t, "Bad Request", body["title"])
+				require.Equal(t, "request-id", body["instance"])
+				require.NotContains(t, body, "detail")
+				require.Len(t, body["errors"], 1)
+				return
+			}
+
+			require.Equal(t, "Bad Request", body["message"])
+			require.EqualValues(t, http.StatusBadRequest, body["code"])
+			require.Len(t, body["errors"], 1)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"fmt"
+	"gofi/database/entity"
+	"gofi/pkg/constant"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ParseQueryOptions reads the page, page size, sort and filter[field] query
+// parameters of a list request. Whether the sort and filter fields exist is
+// left to the repository.
+func ParseQueryOptions(c *fiber.Ctx) (entity.QueryOptions, error) {
+	opts := entity.QueryOptions{
+		Page:     1,
+		PageSize: constant.DefaultPageSize,
+		Sort:     c.Query("sort"),
+		Filters:  map[string]string{},
+	}
+
+	if v := c.Query("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return opts, fmt.Errorf("page must be a positive number")
+		}
+		opts.Page = page
+	}
+
+	if v := c.Query("page_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 || size > constant.MaxPageSize {
+			return opts, fmt.Errorf("page_size must be between 1 and %d", constant.MaxPageSize)
+		}
+		opts.PageSize = size
+	}
+
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		k := string(key)
+		if strings.HasPrefix(k, "filter[") && strings.HasSuffix(k, "]") {
+			opts.Filters[k[len("filter["):len(k)-1]] = string(value)
+		}
+	})
+
+	return opts, nil
+}
@@ -1,6 +1,43 @@
 package utils
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"gofi/database/entity"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PageMeta describes the page of a list response.
+type PageMeta struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+func NewPageMeta(opts entity.QueryOptions, total int) PageMeta {
+	return PageMeta{
+		Page:       opts.Page,
+		PageSize:   opts.PageSize,
+		Total:      total,
+		TotalPages: (total + opts.PageSize - 1) / opts.PageSize,
+	}
+}
+
+// CursorMeta describes a keyset page, Next and Prev link to the pages
+// around it and are null at either end of the list.
+type CursorMeta struct {
+	PageSize int     `json:"page_size"`
+	Next     *string `json:"next"`
+	Prev     *string `json:"prev"`
+}
+
+func NewCursorMeta(c *fiber.Ctx, secret []byte, opts entity.QueryOptions, page entity.Page) CursorMeta {
+	return CursorMeta{
+		PageSize: opts.PageSize,
+		Next:     cursorLink(c, secret, page.Next),
+		Prev:     cursorLink(c, secret, page.Prev),
+	}
+}
 
 func FailureResponse(code int32, message string, errors interface{}) interface{} {
 	return fiber.Map{
@@ -17,3 +54,14 @@ func SuccessResponse(code int32, message string, data interface{}) interface{} {
 		"data":    data,
 	}
 }
+
+// SuccessPageResponse is SuccessResponse for one page of a list, meta is
+// either a PageMeta or a CursorMeta.
+func SuccessPageResponse(code int32, message string, data interface{}, meta interface{}) interface{} {
+	return fiber.Map{
+		"code":    code,
+		"message": message,
+		"data":    data,
+		"meta":    meta,
+	}
+}
@@ -1,71 +1,109 @@
 package utils
 
 import (
-	"fmt"
+	"errors"
+	"log"
 	"net/http"
+	"reflect"
+	"strings"
 
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
 	"github.com/gofiber/fiber/v2"
 )
 
-type (
-	ErrorResponse struct {
-		Error       bool
-		FailedField string
-		Tag         string
-		Value       interface{}
-	}
-)
+// Locales supported by the validation messages, the first one is the fallback.
+var Locales = []string{"en", "id"}
+
+// FieldError is a failure of one field of the request body, named after its
+// json key. Field and Rule are empty when the body as a whole could not be
+// read.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
 
-var validate = validator.New()
+var validate, translator = newValidator()
 
-// validate struct
-func validateStruct(data interface{}) []ErrorResponse {
-	validationErrors := []ErrorResponse{}
+func newValidator() (*validator.Validate, *ut.UniversalTranslator) {
+	v := validator.New()
+
+	// report fields by the name clients send them with
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
 
-	errs := validate.Struct(data)
-	if errs != nil {
-		for _, err := range errs.(validator.ValidationErrors) {
-			// In this case data object is actually holding the User struct
-			var elem ErrorResponse
+	english, indonesian := en.New(), id.New()
+	uni := ut.New(english, english, indonesian)
 
-			elem.FailedField = err.Field() // Export struct field name
-			elem.Tag = err.Tag()           // Export struct tag
-			elem.Value = err.Value()       // Export field value
-			elem.Error = true
+	trans, _ := uni.GetTranslator("en")
+	if err := en_translations.RegisterDefaultTranslations(v, trans); err != nil {
+		log.Fatal(err)
+	}
 
-			validationErrors = append(validationErrors, elem)
-		}
+	trans, _ = uni.GetTranslator("id")
+	if err := id_translations.RegisterDefaultTranslations(v, trans); err != nil {
+		log.Fatal(err)
 	}
 
-	return validationErrors
+	return v, uni
 }
 
-// validate
-func Validate(data interface{}) (code int32, message string, errors []string) {
-	if errs := validateStruct(data); len(errs) > 0 && errs[0].Error {
-		errMsgs := make([]string, 0)
-
-		for _, err := range errs {
-			errMsgs = append(errMsgs, fmt.Sprintf(
-				"[%s]: '%v' | Needs to implement '%s'",
-				err.FailedField,
-				err.Value,
-				err.Tag,
-			))
-		}
+// Locale picks the language of the messages from the Accept-Language header
+// of the request.
+func Locale(c *fiber.Ctx) string {
+	if locale := c.AcceptsLanguages(Locales...); locale != "" {
+		return locale
+	}
+	return Locales[0]
+}
+
+// validate struct
+func validateStruct(data interface{}, locale string) []FieldError {
+	var errs validator.ValidationErrors
+	if err := validate.Struct(data); !errors.As(err, &errs) {
+		return nil
+	}
+
+	trans, _ := translator.GetTranslator(locale)
+
+	fieldErrors := make([]FieldError, 0, len(errs))
+	for _, err := range errs {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   err.Field(),
+			Rule:    err.Tag(),
+			Param:   err.Param(),
+			Message: err.Translate(trans),
+		})
+	}
+
+	return fieldErrors
+}
 
-		return http.StatusBadRequest, fiber.ErrBadRequest.Message, errMsgs
+// validate, the messages are written in the given locale
+func Validate(data interface{}, locale string) (code int32, message string, errors []FieldError) {
+	if errs := validateStruct(data, locale); len(errs) > 0 {
+		return http.StatusBadRequest, fiber.ErrBadRequest.Message, errs
 	}
 
 	return 400, "", nil
 }
 
 // parse form data body
-func ParseFormData(c *fiber.Ctx, body interface{}) (code int32, message string, errors []string) {
+func ParseFormData(c *fiber.Ctx, body interface{}) (code int32, message string, errors []FieldError) {
 	if err := c.BodyParser(body); err != nil {
-		errMsgs := make([]string, 0)
-		errMsgs = append(errMsgs, fiber.ErrUnprocessableEntity.Message)
+		errMsgs := make([]FieldError, 0)
+		errMsgs = append(errMsgs, FieldError{Message: fiber.ErrUnprocessableEntity.Message})
 
 		return http.StatusUnprocessableEntity, fiber.ErrUnprocessableEntity.Message, errMsgs
 	}
@@ -74,10 +112,10 @@ func ParseFormData(c *fiber.Ctx, body interface{}) (code int32, message string,
 }
 
 // parse form data body and validate form
-func ParseFormDataAndValidate(c *fiber.Ctx, body interface{}) (code int32, message string, errors []string) {
+func ParseFormDataAndValidate(c *fiber.Ctx, body interface{}) (code int32, message string, errors []FieldError) {
 	if code, message, errors := ParseFormData(c, body); errors != nil {
 		return code, message, errors
 	}
 
-	return Validate(body)
+	return Validate(body, Locale(c))
 }
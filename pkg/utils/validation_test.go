@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type testValidationReq struct {
+	FullName string `json:"full_name,omitempty" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"min=8"`
+	Internal string `json:"-" validate:"required"`
+}
+
+func TestValidate(t *testing.T) {
+	tcs := []struct {
+		name   string
+		locale string
+		errors []FieldError
+	}{
+		{
+			name:   "english",
+			locale: "en",
+			errors: []FieldError{
+				{Field: "full_name", Rule: "required", Message: "full_name is a required field"},
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+				{Field: "password", Rule: "min", Param: "8", Message: "password must be at least 8 characters in length"},
+			},
+		},
+		{
+			name:   "indonesian",
+			locale: "id",
+			errors: []FieldError{
+				{Field: "full_name", Rule: "required", Message: "full_name wajib diisi"},
+				{Field: "email", Rule: "email", Message: "email harus berupa alamat email yang valid"},
+				{Field: "password", Rule: "min", Param: "8", Message: "panjang minimal password adalah 8 karakter"},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			code, message, errors := Validate(&testValidationReq{Email: "not-an-email", Password: "short", Internal: "set"}, tc.locale)
+
+			require.Equal(t, int32(http.StatusBadRequest), code)
+			require.Equal(t, "Bad Request", message)
+			require.Equal(t, tc.errors, errors)
+		})
+	}
+
+	t.Run("should pass a valid body", func(t *testing.T) {
+		_, _, errors := Validate(&testValidationReq{FullName: "User", Email: "user@example.com", Password: "password", Internal: "set"}, "en")
+		require.Nil(t, errors)
+	})
+
+	t.Run("should report fields without a json name by their go name", func(t *testing.T) {
+		_, _, errors := Validate(&testValidationReq{FullName: "User", Email: "user@example.com", Password: "password"}, "en")
+		require.Equal(t, []FieldError{{Field: "Internal", Rule: "required", Message: "Internal is a required field"}}, errors)
+	})
+}
+
+func TestParseFormDataAndValidate(t *testing.T) {
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		r := new(testValidationReq)
+		r.Internal = "set"
+
+		code, message, errors := ParseFormDataAndValidate(c, r)
+		return c.Status(int(code)).JSON(FailureResponse(code, message, errors))
+	})
+
+	tcs := []struct {
+		name           string
+		acceptLanguage string
+		body           string
+		status         int
+		message        string
+	}{
+		{
+			name:           "indonesian",
+			acceptLanguage: "id",
+			body:           `{"email":"user@example.com","password":"password"}`,
+			status:         http.StatusBadRequest,
+			message:        "full_name wajib diisi",
+		},
+		{
+			name:           "indonesian among other languages",
+			acceptLanguage: "fr;q=0.9, id-ID, en;q=0.5",
+			body:           `{"email":"user@example.com","password":"password"}`,
+			status:         http.StatusBadRequest,
+			message:        "full_name wajib diisi",
+		},
+		{
+			name:           "unsupported language falls back to english",
+			acceptLanguage: "fr",
+			body:           `{"email":"user@example.com","password":"password"}`,
+			status:         http.StatusBadRequest,
+			message:        "full_name is a required field",
+		},
+		{
+			name:    "no language falls back to english",
+			body:    `{"email":"user@example.com","password":"password"}`,
+			status:  http.StatusBadRequest,
+			message: "full_name is a required field",
+		},
+		{
+			name:    "unreadable body",
+			body:    `{"email":`,
+			status:  http.StatusUnprocessableEntity,
+			message: "Unprocessable Entity",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if tc.acceptLanguage != "" {
+				req.Header.Set(fiber.HeaderAcceptLanguage, tc.acceptLanguage)
+			}
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			require.Equal(t, tc.status, res.StatusCode)
+
+			var body struct {
+				Errors []FieldError `json:"errors"`
+			}
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+			require.Len(t, body.Errors, 1)
+			require.Equal(t, tc.message, body.Errors[0].Message)
+		})
+	}
+}
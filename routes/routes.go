@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"gofi/handler"
 	"net/http"
 	"runtime"
 	"time"
@@ -29,6 +30,9 @@ func Routes(db *sqlx.DB, app *fiber.App) {
 		})
 	})
 
+	// public keys of the JWT access tokens
+	handler.JWKSHandler(app)
+
 	app.Get("/v1", func(c *fiber.Ctx) error {
 		return c.Status(http.StatusForbidden).JSON(fiber.NewError(http.StatusForbidden))
 	})
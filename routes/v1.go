@@ -2,6 +2,7 @@ package routes
 
 import (
 	"gofi/handler"
+	"gofi/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
@@ -13,6 +14,37 @@ func v1Route(db *sqlx.DB, app *fiber.App) {
 		return c.Next()
 	})
 
+	// every v1 route requires a session unless listed here
+	v1.Use(middleware.Authorization(db, middleware.AuthConfig{
+		Skip: []string{
+			"/v1/auth/sign-in",
+			"/v1/auth/sign-in/2fa",
+			"/v1/auth/refresh",
+			"/v1/auth/sign-up",
+			"/v1/auth/verify-email",
+			"/v1/auth/forgot-password",
+			"/v1/auth/reset-password",
+			"/v1/auth/oidc/login",
+			"/v1/auth/oidc/callback",
+		},
+		TwoFactorSetup: []string{
+			"/v1/auth/me",
+			"/v1/auth/sign-out",
+			"/v1/auth/2fa/enroll",
+			"/v1/auth/2fa/confirm",
+		},
+	}))
+
+	handler.AuthHandler(db, v1)
+	handler.OIDCHandler(db, v1)
+	handler.TwoFactorHandler(db, v1)
+	handler.UserHandler(db, v1)
+	handler.APIKeyHandler(db, v1)
 	handler.RoleHandler(db, v1)
+	handler.PermissionHandler(db, v1)
 	handler.SessionHandler(db, v1)
+	handler.ProjectHandler(db, v1)
+	handler.TimeEntryHandler(db, v1)
+	handler.TimerHandler(db, v1)
+	handler.TimesheetHandler(db, v1)
 }
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"gofi/pkg/constant"
+	"gofi/pkg/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAPIKey       = apperror.New(apperror.ErrUnauthorized, "api key is invalid or has expired")
+	ErrAPIKeyInvalidScope  = apperror.New(apperror.ErrValidation, "api key scopes must be permissions of your role")
+	ErrAPIKeyInvalidExpiry = apperror.New(apperror.ErrValidation, "api key expiry must be in the future")
+)
+
+type APIKeyService struct {
+	repo           *repository.APIKeyRepository
+	userRepo       *repository.UserRepository
+	permissionRepo *repository.PermissionRepository
+}
+
+func NewAPIKeyService(repo *repository.APIKeyRepository, userRepo *repository.UserRepository, permissionRepo *repository.PermissionRepository) *APIKeyService {
+	return &APIKeyService{
+		repo:           repo,
+		userRepo:       userRepo,
+		permissionRepo: permissionRepo,
+	}
+}
+
+// parseAPIKey splits a "gofi_<prefix>_<secret>" key into its prefix and secret.
+func parseAPIKey(key string) (string, string, bool) {
+	rest, found := strings.CutPrefix(key, constant.APIKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+
+	prefix, secret, found := strings.Cut(rest, "_")
+	if !found || prefix == "" || secret == "" {
+		return "", "", false
+	}
+
+	return prefix, secret, true
+}
+
+// check validates the scopes against the user's role and the expiry of the key.
+func (s *APIKeyService) check(ctx context.Context, user *entity.User, value *entity.APIKey) error {
+	if value.ExpiredAt != nil && !value.ExpiredAt.After(time.Now()) {
+		return ErrAPIKeyInvalidExpiry
+	}
+
+	granted, err := s.permissionRepo.ListPermissionNamesByRole(ctx, user.RoleID)
+	if err != nil {
+		return err
+	}
+
+	for _, scope := range value.Scopes {
+		if !slices.Contains(granted, scope) {
+			return fmt.Errorf("%w: %q", ErrAPIKeyInvalidScope, scope)
+		}
+	}
+
+	slices.Sort(value.Scopes)
+	value.Scopes = slices.Compact(value.Scopes)
+
+	return nil
+}
+
+// CreateAPIKey issues a new key for the user. The full key is returned in
+// Key and cannot be retrieved again.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, user *entity.User, value *entity.APIKey) (*entity.APIKey, error) {
+	if err := s.check(ctx, user, value); err != nil {
+		return nil, err
+	}
+
+	prefix, err := utils.GenerateToken(4)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	value.UserID = user.ID
+	value.Prefix = prefix
+	value.SecretHash = utils.HashToken(secret)
+
+	record, err := s.repo.CreateAPIKey(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Key = constant.APIKeyPrefix + prefix + "_" + secret
+
+	return record, nil
+}
+
+func (s *APIKeyService) GetAPIKey(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entity.APIKey, error) {
+	return s.repo.GetAPIKey(ctx, id, userID)
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]entity.APIKey, error) {
+	return s.repo.ListAPIKeysByUser(ctx, userID)
+}
+
+func (s *APIKeyService) UpdateAPIKey(ctx context.Context, user *entity.User, value *entity.APIKey) (*entity.APIKey, error) {
+	if err := s.check(ctx, user, value); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateAPIKey(ctx, value)
+}
+
+func (s *APIKeyService) DeleteAPIKey(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.repo.GetAPIKey(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteAPIKey(ctx, id, userID)
+}
+
+// Authenticate resolves a personal API key to the key and its owner, and
+// records that the key was used.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*entity.APIKey, *entity.User, error) {
+	prefix, secret, ok := parseAPIKey(key)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	record, err := s.repo.GetAPIKeyByPrefix(ctx, prefix)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(record.SecretHash), []byte(utils.HashToken(secret))) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if record.ExpiredAt != nil && time.Now().UTC().After(*record.ExpiredAt) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetUser(ctx, record.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkUser(user); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.repo.TouchAPIKey(ctx, record.ID); err != nil {
+		return nil, nil, err
+	}
+
+	return record, user, nil
+}
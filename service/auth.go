@@ -0,0 +1,495 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"gofi/pkg/jwt"
+	"gofi/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/masb0ymas/go-utils/argon2"
+)
+
+var (
+	ErrInvalidCredentials = apperror.New(apperror.ErrUnauthorized, "email or password is incorrect")
+	ErrInvalidSession     = apperror.New(apperror.ErrUnauthorized, "session is invalid or has expired")
+	ErrUserInactive       = apperror.New(apperror.ErrForbidden, "user account is not active")
+	ErrUserBlocked        = apperror.New(apperror.ErrForbidden, "user account is blocked")
+	ErrUserLocked         = apperror.New(apperror.ErrLocked, "user account is temporarily locked after too many failed sign-in attempts")
+
+	ErrInvalidRefreshToken = apperror.New(apperror.ErrUnauthorized, "refresh token is invalid or has expired")
+	ErrRefreshTokenReused  = apperror.New(apperror.ErrUnauthorized, "refresh token has already been used, all sessions of this sign-in were revoked")
+	ErrInvalidMFAChallenge = apperror.New(apperror.ErrUnauthorized, "two-factor sign-in has expired or failed too many times, please sign in again")
+)
+
+const (
+	// mfaChallengeExpiresIn is how long the second sign-in step may take.
+	mfaChallengeExpiresIn = 5 * time.Minute
+	// mfaChallengeMaxAttempts is how many wrong codes a challenge tolerates.
+	mfaChallengeMaxAttempts = 5
+
+	// lockoutThreshold is how many consecutive failed sign-ins lock an account.
+	lockoutThreshold = 5
+	// lockoutBaseDuration is the first lock, every further failure doubles it.
+	lockoutBaseDuration = time.Minute
+	// lockoutMaxDuration caps the exponential backoff.
+	lockoutMaxDuration = 24 * time.Hour
+)
+
+// dummyPasswordHash is compared against when the email is unknown, so that a
+// failed sign-in takes the same time whether or not the account exists.
+const dummyPasswordHash = "$argon2id$v=19$m=65536,t=3,p=2$wnMuSBm5Fbw6mo5p4f3I6A$FzqhdZTYyklKziq506MM7cA2Cm7n4ud7GoSXMw6VVnc"
+
+type AuthOptions struct {
+	// SessionExpiresIn bounds the access token of a session.
+	SessionExpiresIn time.Duration
+	// RefreshExpiresIn bounds the refresh token of a session.
+	RefreshExpiresIn time.Duration
+	// KeySet signs access tokens as JWTs. Access tokens are opaque when nil.
+	KeySet *jwt.KeySet
+}
+
+type AuthService struct {
+	userRepo       *repository.UserRepository
+	sessionRepo    *repository.SessionRepository
+	permissionRepo *repository.PermissionRepository
+	twoFactorRepo  *repository.TwoFactorRepository
+	lockoutRepo    *repository.AccountLockoutRepository
+	options        AuthOptions
+}
+
+func NewAuthService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, permissionRepo *repository.PermissionRepository, twoFactorRepo *repository.TwoFactorRepository, lockoutRepo *repository.AccountLockoutRepository, options AuthOptions) *AuthService {
+	return &AuthService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		permissionRepo: permissionRepo,
+		twoFactorRepo:  twoFactorRepo,
+		lockoutRepo:    lockoutRepo,
+		options:        options,
+	}
+}
+
+// lockoutDuration is how long the account is locked after the given number
+// of consecutive failed sign-ins, zero while still under the threshold.
+func lockoutDuration(failures int) time.Duration {
+	if failures < lockoutThreshold {
+		return 0
+	}
+
+	duration := lockoutBaseDuration
+	for i := lockoutThreshold; i < failures; i++ {
+		duration *= 2
+		if duration >= lockoutMaxDuration {
+			return lockoutMaxDuration
+		}
+	}
+
+	return duration
+}
+
+// recordFailedSignIn counts the failure and locks the account once the
+// threshold is reached.
+func (s *AuthService) recordFailedSignIn(ctx context.Context, user *entity.User) error {
+	failures, err := s.lockoutRepo.RecordFailedSignIn(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	duration := lockoutDuration(failures)
+	if duration == 0 {
+		return nil
+	}
+
+	_, err = s.lockoutRepo.LockUser(ctx, &entity.AccountLockout{
+		UserID:           user.ID,
+		FailedLoginCount: failures,
+		LockedUntil:      time.Now().UTC().Add(duration),
+	})
+
+	return err
+}
+
+// clearFailedSignIns forgets the failed attempts of a user who signed in.
+func (s *AuthService) clearFailedSignIns(ctx context.Context, user *entity.User) error {
+	if user.FailedLoginCount == 0 && user.LockedUntil == nil {
+		return nil
+	}
+
+	return s.lockoutRepo.ResetFailedSignIns(ctx, user.ID)
+}
+
+// checkUser fails when the user may not hold a session.
+func checkUser(user *entity.User) error {
+	if !user.IsActive {
+		return ErrUserInactive
+	}
+
+	if user.IsBlocked {
+		return ErrUserBlocked
+	}
+
+	return nil
+}
+
+// accessToken returns a signed JWT carrying the user's role and permissions
+// when a key set is configured, and an opaque random token otherwise.
+func (s *AuthService) accessToken(ctx context.Context, user *entity.User, familyID uuid.UUID, issuedAt time.Time, expiredAt time.Time) (string, error) {
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if s.options.KeySet == nil {
+		return token, nil
+	}
+
+	permissions, err := s.permissionRepo.ListPermissionNamesByRole(ctx, user.RoleID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.options.KeySet.Sign(jwt.Claims{
+		Subject:     user.ID.String(),
+		IssuedAt:    issuedAt.Unix(),
+		ExpiresAt:   expiredAt.Unix(),
+		ID:          token,
+		SessionID:   familyID.String(),
+		Role:        user.RoleID.String(),
+		Permissions: permissions,
+	})
+}
+
+// newSession issues a fresh access token and refresh token for the user in
+// the given session family. Only the hash of the refresh token is kept on the
+// session, the plain token is returned in RefreshToken.
+func (s *AuthService) newSession(ctx context.Context, user *entity.User, familyID uuid.UUID) (*entity.Session, error) {
+	now := time.Now().UTC()
+	expiredAt := now.Add(s.options.SessionExpiresIn)
+
+	token, err := s.accessToken(ctx, user, familyID, now, expiredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenHash := utils.HashToken(refreshToken)
+	refreshExpiredAt := now.Add(s.options.RefreshExpiresIn)
+
+	return &entity.Session{
+		UserID:           user.ID,
+		Token:            token,
+		ExpiredAt:        expiredAt,
+		RefreshTokenHash: &refreshTokenHash,
+		RefreshExpiredAt: &refreshExpiredAt,
+		FamilyID:         familyID,
+		RefreshToken:     refreshToken,
+	}, nil
+}
+
+// SignIn verifies the credentials and opens a new session for the user.
+// When the user has two-factor authentication enabled no session is created
+// yet; a challenge is returned instead, to be completed with CompleteSignIn.
+func (s *AuthService) SignIn(ctx context.Context, email string, password string, client entity.ClientInfo) (*entity.Session, *entity.MFAChallenge, *entity.User, error) {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		argon2.Compare(password, dummyPasswordHash)
+		return nil, nil, nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// a locked account does not even get its password checked
+	if user.LockedUntil != nil && time.Now().UTC().Before(*user.LockedUntil) {
+		return nil, nil, nil, ErrUserLocked
+	}
+
+	match, err := argon2.Compare(password, user.Password)
+	if err != nil || !match {
+		if err := s.recordFailedSignIn(ctx, user); err != nil {
+			return nil, nil, nil, err
+		}
+
+		return nil, nil, nil, ErrInvalidCredentials
+	}
+
+	session, challenge, user, err := s.SignInUser(ctx, user, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// with two-factor authentication the failures are only cleared once the
+	// code is right too
+	if challenge == nil {
+		if err := s.clearFailedSignIns(ctx, user); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return session, challenge, user, nil
+}
+
+// SignInUser signs in a user whose identity was already established, by
+// password or by an external provider. Like SignIn, it returns a challenge
+// instead of a session when the user has two-factor authentication enabled.
+func (s *AuthService) SignInUser(ctx context.Context, user *entity.User, client entity.ClientInfo) (*entity.Session, *entity.MFAChallenge, *entity.User, error) {
+	if err := checkUser(user); err != nil {
+		return nil, nil, nil, err
+	}
+
+	enabled, err := s.twoFactorRepo.IsTwoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if enabled {
+		challenge, err := s.newChallenge(ctx, user)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		return nil, challenge, user, nil
+	}
+
+	session, err := s.startSession(ctx, user, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return session, nil, user, nil
+}
+
+// CompleteSignIn finishes a two-factor sign-in with an authenticator or
+// recovery code and opens the session.
+func (s *AuthService) CompleteSignIn(ctx context.Context, mfaToken string, code string, client entity.ClientInfo) (*entity.Session, *entity.User, error) {
+	challenge, err := s.twoFactorRepo.GetMFAChallengeByTokenHash(ctx, utils.HashToken(mfaToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if time.Now().UTC().After(challenge.ExpiredAt) || challenge.Attempts >= mfaChallengeMaxAttempts {
+		if err := s.twoFactorRepo.DeleteMFAChallenge(ctx, challenge.ID); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+
+	user, err := s.userRepo.GetUser(ctx, challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.LockedUntil != nil && time.Now().UTC().Before(*user.LockedUntil) {
+		return nil, nil, ErrUserLocked
+	}
+
+	if err := verifyTwoFactorCode(ctx, s.twoFactorRepo, challenge.UserID, code); err != nil {
+		// wrong codes count toward the lockout like wrong passwords, a fresh
+		// challenge does not start the count over
+		if errors.Is(err, ErrTwoFactorInvalidCode) {
+			if err := s.twoFactorRepo.IncrementMFAChallengeAttempts(ctx, challenge.ID); err != nil {
+				return nil, nil, err
+			}
+
+			if err := s.recordFailedSignIn(ctx, user); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// a wrong or missing second factor fails the sign-in itself
+		if errors.Is(err, ErrTwoFactorInvalidCode) || errors.Is(err, ErrTwoFactorNotEnrolled) {
+			return nil, nil, apperror.Wrap(apperror.ErrUnauthorized, err.Error(), err)
+		}
+
+		return nil, nil, err
+	}
+
+	if err := s.twoFactorRepo.DeleteMFAChallenge(ctx, challenge.ID); err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkUser(user); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.clearFailedSignIns(ctx, user); err != nil {
+		return nil, nil, err
+	}
+
+	session, err := s.startSession(ctx, user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session, user, nil
+}
+
+// newChallenge opens the second step of a two-factor sign-in.
+func (s *AuthService) newChallenge(ctx context.Context, user *entity.User) (*entity.MFAChallenge, error) {
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.twoFactorRepo.CreateMFAChallenge(ctx, &entity.MFAChallenge{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiredAt: time.Now().UTC().Add(mfaChallengeExpiresIn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	challenge.Token = token
+
+	return challenge, nil
+}
+
+// startSession opens a session in a new family, every sign-in starts one.
+// The client it is opened from is recorded so the user can recognise it.
+func (s *AuthService) startSession(ctx context.Context, user *entity.User, client entity.ClientInfo) (*entity.Session, error) {
+	value, err := s.newSession(ctx, user, uuid.New())
+	if err != nil {
+		return nil, err
+	}
+
+	value.IPAddress = optionalString(client.IPAddress)
+	value.UserAgent = optionalString(client.UserAgent)
+	value.Device = optionalString(utils.DeviceName(client.UserAgent))
+
+	return s.sessionRepo.CreateSession(ctx, value)
+}
+
+// Refresh trades a refresh token for a new session in the same family,
+// revoking the old one. Presenting a refresh token that was already rotated
+// means it leaked, so the whole family is revoked.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*entity.Session, *entity.User, error) {
+	if refreshToken == "" {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	current, err := s.sessionRepo.GetSessionByRefreshTokenHash(ctx, utils.HashToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if current.RevokedAt != nil {
+		if err := s.sessionRepo.RevokeSessionFamily(ctx, current.FamilyID); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, ErrRefreshTokenReused
+	}
+
+	if current.RefreshExpiredAt == nil || time.Now().UTC().After(*current.RefreshExpiredAt) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetUser(ctx, current.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkUser(user); err != nil {
+		return nil, nil, err
+	}
+
+	value, err := s.newSession(ctx, user, current.FamilyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// a rotation continues the session opened at sign-in on the same client
+	value.IPAddress = current.IPAddress
+	value.UserAgent = current.UserAgent
+	value.Device = current.Device
+
+	session, err := s.sessionRepo.RotateSession(ctx, current.ID, value)
+	if errors.Is(err, sql.ErrNoRows) {
+		// lost the race against another rotation of the same token
+		if err := s.sessionRepo.RevokeSessionFamily(ctx, current.FamilyID); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session, user, nil
+}
+
+// Authenticate resolves an unexpired session token to its session and user.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*entity.Session, *entity.User, error) {
+	if token == "" {
+		return nil, nil, ErrInvalidSession
+	}
+
+	// a JWT is checked against the published keys before touching the database
+	if s.options.KeySet != nil && jwt.IsToken(token) {
+		if _, err := s.options.KeySet.Verify(token); err != nil {
+			return nil, nil, ErrInvalidSession
+		}
+	}
+
+	session, err := s.sessionRepo.GetSessionByToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidSession
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if session.RevokedAt != nil || time.Now().UTC().After(session.ExpiredAt) {
+		return nil, nil, ErrInvalidSession
+	}
+
+	user, err := s.userRepo.GetUser(ctx, session.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidSession
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkUser(user); err != nil {
+		return nil, nil, err
+	}
+
+	return session, user, nil
+}
+
+// optionalString maps an empty value to NULL.
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+// SignOut revokes the session together with every session rotated from the
+// same sign-in, so its refresh token can no longer be used either.
+func (s *AuthService) SignOut(ctx context.Context, session *entity.Session) error {
+	return s.sessionRepo.RevokeSessionFamily(ctx, session.FamilyID)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"gofi/database/repository"
+	"log"
+	"time"
+)
+
+// CleanupService removes rows that are past their expiry and no longer
+// serve any purpose.
+type CleanupService struct {
+	sessionRepo       *repository.SessionRepository
+	twoFactorRepo     *repository.TwoFactorRepository
+	oidcRepo          *repository.OIDCRepository
+	passwordResetRepo *repository.PasswordResetRepository
+}
+
+func NewCleanupService(sessionRepo *repository.SessionRepository, twoFactorRepo *repository.TwoFactorRepository, oidcRepo *repository.OIDCRepository, passwordResetRepo *repository.PasswordResetRepository) *CleanupService {
+	return &CleanupService{
+		sessionRepo:       sessionRepo,
+		twoFactorRepo:     twoFactorRepo,
+		oidcRepo:          oidcRepo,
+		passwordResetRepo: passwordResetRepo,
+	}
+}
+
+// PurgeExpiredSessions deletes the sessions that can no longer be used or refreshed.
+func (s *CleanupService) PurgeExpiredSessions(ctx context.Context) error {
+	purged, err := s.sessionRepo.PurgeExpiredSessions(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		log.Printf("purged %d expired sessions", purged)
+	}
+
+	return nil
+}
+
+// PurgeExpiredTokens deletes expired two-factor challenges, pending single
+// sign-ons and password reset tokens.
+func (s *CleanupService) PurgeExpiredTokens(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	challenges, err := s.twoFactorRepo.PurgeExpiredMFAChallenges(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	logins, err := s.oidcRepo.PurgeExpiredOIDCLogins(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	resets, err := s.passwordResetRepo.PurgeExpiredPasswordResets(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	if challenges+logins+resets > 0 {
+		log.Printf("purged %d mfa challenges, %d oidc logins and %d password resets", challenges, logins, resets)
+	}
+
+	return nil
+}
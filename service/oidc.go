@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"gofi/pkg/constant"
+	"gofi/pkg/oidc"
+	"gofi/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/masb0ymas/go-utils/argon2"
+)
+
+var (
+	ErrOIDCInvalidState     = apperror.New(apperror.ErrUnauthorized, "single sign-on request is invalid or has expired, please try again")
+	ErrOIDCInvalidToken     = apperror.New(apperror.ErrUnauthorized, "identity provider returned an invalid id token")
+	ErrOIDCEmailNotVerified = apperror.New(apperror.ErrUnauthorized, "identity provider did not return a verified email address")
+)
+
+// oidcLoginExpiresIn is how long the user may take at the identity provider.
+const oidcLoginExpiresIn = 10 * time.Minute
+
+type OIDCService struct {
+	provider      *oidc.Provider
+	repo          *repository.OIDCRepository
+	userRepo      *repository.UserRepository
+	authService   *AuthService
+	defaultRoleID uuid.UUID
+}
+
+// NewOIDCService builds the service. Users signing in for the first time
+// without a matching account are provisioned with defaultRoleID.
+func NewOIDCService(provider *oidc.Provider, repo *repository.OIDCRepository, userRepo *repository.UserRepository, authService *AuthService, defaultRoleID uuid.UUID) *OIDCService {
+	return &OIDCService{
+		provider:      provider,
+		repo:          repo,
+		userRepo:      userRepo,
+		authService:   authService,
+		defaultRoleID: defaultRoleID,
+	}
+}
+
+// Login starts a sign-on and returns the provider URL to send the user to.
+func (s *OIDCService) Login(ctx context.Context) (string, error) {
+	state, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	verifier, err := oidc.GenerateVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.repo.CreateOIDCLogin(ctx, &entity.OIDCLogin{
+		StateHash:    utils.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiredAt:    time.Now().UTC().Add(oidcLoginExpiresIn),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return s.provider.AuthCodeURL(ctx, state, nonce, oidc.Challenge(verifier))
+}
+
+// Callback completes the sign-on: it exchanges the code, verifies the ID
+// token and signs the linked, matched or newly provisioned user in.
+func (s *OIDCService) Callback(ctx context.Context, code string, state string, client entity.ClientInfo) (*entity.Session, *entity.MFAChallenge, *entity.User, error) {
+	if code == "" || state == "" {
+		return nil, nil, nil, ErrOIDCInvalidState
+	}
+
+	login, err := s.repo.TakeOIDCLogin(ctx, utils.HashToken(state))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil, ErrOIDCInvalidState
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if time.Now().UTC().After(login.ExpiredAt) {
+		return nil, nil, nil, ErrOIDCInvalidState
+	}
+
+	token, err := s.provider.Exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	claims, err := s.provider.VerifyIDToken(ctx, token.IDToken, login.Nonce)
+	if errors.Is(err, oidc.ErrInvalidIDToken) || errors.Is(err, oidc.ErrUnknownKey) {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrOIDCInvalidToken, err)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	user, err := s.resolveUser(ctx, claims)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return s.authService.SignInUser(ctx, user, client)
+}
+
+// resolveUser finds the user linked to the provider account. An unlinked
+// account is linked to the user with the same verified email, or a new user
+// is provisioned when there is none.
+func (s *OIDCService) resolveUser(ctx context.Context, claims *oidc.Claims) (*entity.User, error) {
+	identity, err := s.repo.GetUserIdentity(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		user, err := s.userRepo.GetUser(ctx, identity.UserID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// the linked user has been deleted
+			return nil, ErrInvalidCredentials
+		}
+
+		return user, err
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// linking by email is only safe when the provider vouches for the address
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	identity = &entity.UserIdentity{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   &claims.Email,
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, claims.Email)
+	if err == nil {
+		identity.UserID = user.ID
+		if _, err := s.repo.CreateUserIdentity(ctx, identity); err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// provisioned users sign in through the provider, the random password
+	// only satisfies the column and is never handed out
+	password, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	fullname := claims.Name
+	if fullname == "" {
+		fullname = claims.Email
+	}
+
+	return s.repo.ProvisionUser(ctx, &entity.User{
+		Fullname: fullname,
+		Email:    claims.Email,
+		Password: argon2.Generate(password),
+		IsActive: true,
+		RoleID:   s.defaultRoleID,
+		Timezone: constant.DefaultTimezone,
+	}, identity)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"gofi/pkg/mailer"
+	"gofi/pkg/utils"
+	"log"
+	"time"
+
+	"github.com/masb0ymas/go-utils/argon2"
+)
+
+var ErrInvalidResetToken = apperror.New(apperror.ErrValidation, "password reset link is invalid, expired or has already been used")
+
+const (
+	// passwordResetExpiresIn is how long a password reset link stays valid.
+	passwordResetExpiresIn = time.Hour
+
+	// passwordResetSendTimeout bounds creating and emailing a reset link.
+	passwordResetSendTimeout = time.Minute
+)
+
+type PasswordResetService struct {
+	repo     *repository.PasswordResetRepository
+	userRepo *repository.UserRepository
+	mailer   mailer.Mailer
+	resetURL string
+}
+
+// NewPasswordResetService builds the service. resetURL is the page the email
+// links to; the token is appended to it as the "token" query parameter.
+func NewPasswordResetService(repo *repository.PasswordResetRepository, userRepo *repository.UserRepository, mailer mailer.Mailer, resetURL string) *PasswordResetService {
+	return &PasswordResetService{
+		repo:     repo,
+		userRepo: userRepo,
+		mailer:   mailer,
+		resetURL: resetURL,
+	}
+}
+
+// ForgotPassword emails a reset link to the user owning the address. It
+// succeeds for unknown addresses too, so callers cannot probe for accounts.
+func (s *PasswordResetService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// the link is created and sent in the background, so known and unknown
+	// addresses answer equally fast and a slow mail server cannot hold the
+	// request
+	go s.sendResetLink(user)
+
+	return nil
+}
+
+// sendResetLink creates a reset token for the user and emails the link. The
+// answer is the same whether the user exists or not, so failures are only
+// logged.
+func (s *PasswordResetService) sendResetLink(user *entity.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), passwordResetSendTimeout)
+	defer cancel()
+
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		log.Printf("error generating password reset token for %s: %v", user.Email, err)
+		return
+	}
+
+	_, err = s.repo.CreatePasswordReset(ctx, &entity.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiredAt: time.Now().UTC().Add(passwordResetExpiresIn),
+	})
+	if err != nil {
+		log.Printf("error creating password reset for %s: %v", user.Email, err)
+		return
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. Open the link below within %s to choose a new one:\n\n%s?token=%s\n\nIf it was not you, you can ignore this email.\n", user.Fullname, passwordResetExpiresIn, s.resetURL, token)
+
+	if err := s.mailer.Send(ctx, user.Email, "Reset your password", body); err != nil {
+		log.Printf("error sending password reset email to %s: %v", user.Email, err)
+	}
+}
+
+// ResetPassword sets a new password with a reset token and signs the user out
+// everywhere. Each token can be used once.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token string, password string) error {
+	reset, err := s.repo.GetPasswordResetByTokenHash(ctx, utils.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	if reset.UsedAt != nil || time.Now().UTC().After(reset.ExpiredAt) {
+		return ErrInvalidResetToken
+	}
+
+	err = s.repo.ResetPassword(ctx, reset.ID, reset.UserID, argon2.Generate(password))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidResetToken
+	}
+
+	return err
+}
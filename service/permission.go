@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"gofi/database/entity"
+	"gofi/database/repository"
+
+	"github.com/google/uuid"
+)
+
+type PermissionService struct {
+	repo     *repository.PermissionRepository
+	roleRepo *repository.RoleRepository
+}
+
+func NewPermissionService(repo *repository.PermissionRepository, roleRepo *repository.RoleRepository) *PermissionService {
+	return &PermissionService{
+		repo:     repo,
+		roleRepo: roleRepo,
+	}
+}
+
+func (s *PermissionService) ListPermissions(ctx context.Context) ([]entity.Permission, error) {
+	return s.repo.ListPermissions(ctx)
+}
+
+func (s *PermissionService) ListRolePermissions(ctx context.Context, roleID uuid.UUID) ([]entity.Permission, error) {
+	if _, err := s.roleRepo.GetRole(ctx, roleID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListRolePermissions(ctx, roleID)
+}
+
+// GrantPermission gives the role the permission and returns the role's
+// permissions after the change.
+func (s *PermissionService) GrantPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) ([]entity.Permission, error) {
+	if _, err := s.roleRepo.GetRole(ctx, roleID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetPermission(ctx, permissionID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.GrantPermission(ctx, roleID, permissionID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListRolePermissions(ctx, roleID)
+}
+
+func (s *PermissionService) RevokePermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	if _, err := s.roleRepo.GetRole(ctx, roleID); err != nil {
+		return err
+	}
+
+	return s.repo.RevokePermission(ctx, roleID, permissionID)
+}
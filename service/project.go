@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrProjectForbidden = apperror.New(apperror.ErrForbidden, "you do not have access to this project")
+	ErrProjectNotOwner  = apperror.New(apperror.ErrForbidden, "only the project owner can change this project")
+	ErrProjectInUse     = apperror.New(apperror.ErrConflict, "project still has time entries")
+)
+
+type ProjectService struct {
+	repo *repository.ProjectRepository
+}
+
+func NewProjectService(repo *repository.ProjectRepository) *ProjectService {
+	return &ProjectService{
+		repo: repo,
+	}
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, value *entity.Project) (*entity.Project, error) {
+	return s.repo.CreateProject(ctx, value)
+}
+
+// GetProject returns the project when the user owns it or is one of its members.
+func (s *ProjectService) GetProject(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entity.Project, error) {
+	record, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.OwnerID == userID {
+		return record, nil
+	}
+
+	member, err := s.repo.IsProjectMember(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !member {
+		return nil, ErrProjectForbidden
+	}
+
+	return record, nil
+}
+
+// GetOwnedProject returns the project only when the user is its owner.
+func (s *ProjectService) GetOwnedProject(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entity.Project, error) {
+	record, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.OwnerID != userID {
+		return nil, ErrProjectNotOwner
+	}
+
+	return record, nil
+}
+
+// ListProjects lists the projects of the user, or every project, soft deleted
+// or not, with withDeleted.
+func (s *ProjectService) ListProjects(ctx context.Context, userID uuid.UUID, withDeleted bool, opts entity.QueryOptions) ([]entity.Project, entity.Page, error) {
+	return s.repo.ListProjectsByUser(ctx, userID, withDeleted, opts)
+}
+
+func (s *ProjectService) UpdateProject(ctx context.Context, value *entity.Project) (*entity.Project, error) {
+	return s.repo.UpdateProject(ctx, value)
+}
+
+// DeleteProject soft deletes a project of the user. With anyOwner, for those
+// who can purge projects, the project may belong to anyone.
+func (s *ProjectService) DeleteProject(ctx context.Context, id uuid.UUID, userID uuid.UUID, anyOwner bool) error {
+	if !anyOwner {
+		if _, err := s.GetOwnedProject(ctx, id, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.DeleteProject(ctx, id, userID, anyOwner)
+}
+
+// RestoreProject brings back a soft deleted project of the user, or of anyone
+// with anyOwner.
+func (s *ProjectService) RestoreProject(ctx context.Context, id uuid.UUID, userID uuid.UUID, anyOwner bool) (*entity.Project, error) {
+	return s.repo.RestoreProject(ctx, id, userID, anyOwner)
+}
+
+// ForceDeleteProject permanently deletes a project, which fails while time
+// entries are still booked on it.
+func (s *ProjectService) ForceDeleteProject(ctx context.Context, id uuid.UUID) error {
+	err := s.repo.ForceDeleteProject(ctx, id)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrProjectInUse
+	}
+
+	return err
+}
+
+func (s *ProjectService) ListProjectMembers(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]entity.ProjectMember, error) {
+	if _, err := s.GetProject(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListProjectMembers(ctx, id)
+}
+
+func (s *ProjectService) AddProjectMember(ctx context.Context, id uuid.UUID, userID uuid.UUID, memberID uuid.UUID) (*entity.ProjectMember, error) {
+	if _, err := s.GetOwnedProject(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.AddProjectMember(ctx, &entity.ProjectMember{
+		ProjectID: id,
+		UserID:    memberID,
+	})
+}
+
+func (s *ProjectService) RemoveProjectMember(ctx context.Context, id uuid.UUID, userID uuid.UUID, memberID uuid.UUID) error {
+	if _, err := s.GetOwnedProject(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return s.repo.RemoveProjectMember(ctx, id, memberID)
+}
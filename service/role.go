@@ -2,10 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
 	"gofi/database/entity"
 	"gofi/database/repository"
+	"gofi/pkg/apperror"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrRoleInUse = apperror.New(apperror.ErrConflict, "role is still assigned to users")
 )
 
 type RoleService struct {
@@ -26,14 +33,46 @@ func (s *RoleService) GetRole(ctx context.Context, id uuid.UUID) (*entity.Role,
 	return s.repo.GetRole(ctx, id)
 }
 
-func (s *RoleService) ListRoles(ctx context.Context) ([]entity.Role, error) {
-	return s.repo.ListRoles(ctx)
+func (s *RoleService) GetRoleByName(ctx context.Context, name string) (*entity.Role, error) {
+	return s.repo.GetRoleByName(ctx, name)
+}
+
+func (s *RoleService) ListRoles(ctx context.Context, withDeleted bool, opts entity.QueryOptions) ([]entity.Role, int, error) {
+	return s.repo.ListRoles(ctx, withDeleted, opts)
 }
 
 func (s *RoleService) UpdateRole(ctx context.Context, value *entity.Role) (*entity.Role, error) {
 	return s.repo.UpdateRole(ctx, value)
 }
 
+// DeleteRole soft deletes the role. A role its users still rely on to sign in
+// cannot be deleted.
 func (s *RoleService) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	inUse, err := s.repo.IsRoleInUse(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if inUse {
+		return ErrRoleInUse
+	}
+
 	return s.repo.DeleteRole(ctx, id)
 }
+
+func (s *RoleService) RestoreRole(ctx context.Context, id uuid.UUID) (*entity.Role, error) {
+	return s.repo.RestoreRole(ctx, id)
+}
+
+// ForceDeleteRole permanently deletes the role, which fails while any user,
+// deleted or not, still references it.
+func (s *RoleService) ForceDeleteRole(ctx context.Context, id uuid.UUID) error {
+	err := s.repo.ForceDeleteRole(ctx, id)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrRoleInUse
+	}
+
+	return err
+}
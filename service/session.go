@@ -18,7 +18,13 @@ func NewSessionService(repo *repository.SessionRepository) *SessionService {
 	}
 }
 
+// CreateSession stores the session, starting a new family when it is not
+// part of one yet.
 func (s *SessionService) CreateSession(ctx context.Context, value *entity.Session) (*entity.Session, error) {
+	if value.FamilyID == uuid.Nil {
+		value.FamilyID = uuid.New()
+	}
+
 	return s.repo.CreateSession(ctx, value)
 }
 
@@ -26,8 +32,24 @@ func (s *SessionService) GetSession(ctx context.Context, id uuid.UUID, token str
 	return s.repo.GetSession(ctx, id, token)
 }
 
-func (s *SessionService) ListSessions(ctx context.Context) ([]entity.Session, error) {
-	return s.repo.ListSessions(ctx)
+func (s *SessionService) ListSessions(ctx context.Context, opts entity.QueryOptions) ([]entity.Session, entity.Page, error) {
+	return s.repo.ListSessions(ctx, opts)
+}
+
+// ListUserSessions lists the active sessions of the user.
+func (s *SessionService) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]entity.Session, error) {
+	return s.repo.ListActiveSessionsByUser(ctx, userID)
+}
+
+// RevokeUserSession signs the user out of one of their sessions.
+func (s *SessionService) RevokeUserSession(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	return s.repo.RevokeUserSession(ctx, id, userID)
+}
+
+// RevokeOtherSessions signs the user out everywhere except the current
+// session and returns how many sessions were revoked.
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, current *entity.Session) (int64, error) {
+	return s.repo.RevokeOtherSessions(ctx, userID, current.FamilyID)
 }
 
 func (s *SessionService) UpdateSession(ctx context.Context, value *entity.Session) (*entity.Session, error) {
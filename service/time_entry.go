@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"gofi/pkg/constant"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrTimeEntryInvalidRange = apperror.New(apperror.ErrValidation, "time entry must end after it starts")
+	ErrTimeEntryOverlap      = apperror.New(apperror.ErrConflict, "time entry overlaps with another entry of the same user")
+	ErrTimeEntryLocked       = apperror.New(apperror.ErrLocked, "time entry belongs to a submitted or approved timesheet")
+	ErrTimeEntryForbidden    = apperror.New(apperror.ErrForbidden, "time entry belongs to another user")
+)
+
+type TimeEntryService struct {
+	repo          *repository.TimeEntryRepository
+	timesheetRepo *repository.TimesheetRepository
+	projectRepo   *repository.ProjectRepository
+	userRepo      *repository.UserRepository
+}
+
+func NewTimeEntryService(repo *repository.TimeEntryRepository, timesheetRepo *repository.TimesheetRepository, projectRepo *repository.ProjectRepository, userRepo *repository.UserRepository) *TimeEntryService {
+	return &TimeEntryService{
+		repo:          repo,
+		timesheetRepo: timesheetRepo,
+		projectRepo:   projectRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// checkProjectAccess fails unless the user owns the project or is one of its
+// members, time can only be booked on such projects.
+func checkProjectAccess(ctx context.Context, repo *repository.ProjectRepository, projectID uuid.UUID, userID uuid.UUID) error {
+	project, err := repo.GetProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	if project.OwnerID == userID {
+		return nil
+	}
+
+	member, err := repo.IsProjectMember(ctx, projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !member {
+		return ErrProjectForbidden
+	}
+
+	return nil
+}
+
+// overlapError maps a violation of the overlap exclusion constraint, hit when
+// a concurrent request slipped past the overlap check, to ErrTimeEntryOverlap.
+func overlapError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23P01" {
+		return ErrTimeEntryOverlap
+	}
+
+	return err
+}
+
+// weekLocation returns the timezone of the user, their ISO weeks start on
+// Monday midnight there.
+func weekLocation(ctx context.Context, repo *repository.UserRepository, userID uuid.UUID) (*time.Location, error) {
+	user, err := repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return nil, ErrUserInvalidTimezone
+	}
+
+	return loc, nil
+}
+
+// checkUnlocked fails when any ISO week touched by [from, to), counted in the
+// user's timezone, is submitted or approved on the user's timesheet, the
+// entries of those weeks are read-only.
+func checkUnlocked(ctx context.Context, repo *repository.TimesheetRepository, userID uuid.UUID, loc *time.Location, from time.Time, to time.Time) error {
+	from, to = from.In(loc), to.In(loc)
+
+	// the entry ends right before to, so to itself only counts for an instant
+	last := to
+	if to.After(from) {
+		last = to.Add(-time.Nanosecond)
+	}
+
+	var year, week int
+	for t := from; ; t = t.AddDate(0, 0, 7) {
+		if t.After(last) {
+			t = last
+		}
+
+		if y, w := t.ISOWeek(); y != year || w != week {
+			year, week = y, w
+
+			timesheet, err := repo.GetTimesheetByWeek(ctx, userID, year, week)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			if err == nil && (timesheet.Status == constant.TimesheetStatusSubmitted || timesheet.Status == constant.TimesheetStatusApproved) {
+				return ErrTimeEntryLocked
+			}
+		}
+
+		if t.Equal(last) {
+			return nil
+		}
+	}
+}
+
+// checkEntryUnlocked checks every week the entry spans, a running entry spans
+// up to now.
+func (s *TimeEntryService) checkEntryUnlocked(ctx context.Context, value *entity.TimeEntry) error {
+	endedAt := time.Now().UTC()
+	if value.EndedAt != nil {
+		endedAt = *value.EndedAt
+	}
+
+	loc, err := weekLocation(ctx, s.userRepo, value.UserID)
+	if err != nil {
+		return err
+	}
+
+	return checkUnlocked(ctx, s.timesheetRepo, value.UserID, loc, value.StartedAt, endedAt)
+}
+
+// validate checks the entry's range and makes sure it does not overlap with
+// any other entry of the same user, then refreshes the stored duration.
+func (s *TimeEntryService) validate(ctx context.Context, value *entity.TimeEntry) error {
+	if value.EndedAt == nil || !value.EndedAt.After(value.StartedAt) {
+		return ErrTimeEntryInvalidRange
+	}
+
+	total, err := s.repo.CountOverlappingTimeEntries(ctx, value.UserID, value.StartedAt, *value.EndedAt, value.ID)
+	if err != nil {
+		return err
+	}
+
+	if total > 0 {
+		return ErrTimeEntryOverlap
+	}
+
+	value.Duration = int64(value.EndedAt.Sub(value.StartedAt).Seconds())
+
+	return nil
+}
+
+func (s *TimeEntryService) CreateTimeEntry(ctx context.Context, value *entity.TimeEntry) (*entity.TimeEntry, error) {
+	if err := s.validate(ctx, value); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEntryUnlocked(ctx, value); err != nil {
+		return nil, err
+	}
+
+	if err := checkProjectAccess(ctx, s.projectRepo, value.ProjectID, value.UserID); err != nil {
+		return nil, err
+	}
+
+	record, err := s.repo.CreateTimeEntry(ctx, value)
+	if err != nil {
+		return nil, overlapError(err)
+	}
+
+	return record, nil
+}
+
+// GetTimeEntry returns the entry only when it belongs to the user.
+func (s *TimeEntryService) GetTimeEntry(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*entity.TimeEntry, error) {
+	record, err := s.repo.GetTimeEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.UserID != userID {
+		return nil, ErrTimeEntryForbidden
+	}
+
+	return record, nil
+}
+
+func (s *TimeEntryService) ListTimeEntries(ctx context.Context, userID uuid.UUID, opts entity.QueryOptions) ([]entity.TimeEntry, int, error) {
+	return s.repo.ListTimeEntriesByUser(ctx, userID, opts)
+}
+
+func (s *TimeEntryService) UpdateTimeEntry(ctx context.Context, value *entity.TimeEntry) (*entity.TimeEntry, error) {
+	current, err := s.repo.GetTimeEntry(ctx, value.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validate(ctx, value); err != nil {
+		return nil, err
+	}
+
+	// neither the weeks the entry leaves nor the ones it moves into may be locked
+	if err := s.checkEntryUnlocked(ctx, current); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEntryUnlocked(ctx, value); err != nil {
+		return nil, err
+	}
+
+	if value.ProjectID != current.ProjectID {
+		if err := checkProjectAccess(ctx, s.projectRepo, value.ProjectID, value.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := s.repo.UpdateTimeEntry(ctx, value)
+	if err != nil {
+		return nil, overlapError(err)
+	}
+
+	return record, nil
+}
+
+func (s *TimeEntryService) DeleteTimeEntry(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	current, err := s.GetTimeEntry(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkEntryUnlocked(ctx, current); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteTimeEntry(ctx, id)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrTimerNotRunning      = apperror.New(apperror.ErrNotFound, "there is no running timer")
+	ErrTimerAlreadyRunning  = apperror.New(apperror.ErrConflict, "another timer was started at the same time")
+	ErrTimerNothingToResume = apperror.New(apperror.ErrNotFound, "there is no stopped timer to resume")
+	ErrTimerInvalidTimezone = apperror.New(apperror.ErrValidation, "unknown timezone")
+)
+
+type TimerService struct {
+	repo          *repository.TimeEntryRepository
+	timesheetRepo *repository.TimesheetRepository
+	projectRepo   *repository.ProjectRepository
+	userRepo      *repository.UserRepository
+}
+
+func NewTimerService(repo *repository.TimeEntryRepository, timesheetRepo *repository.TimesheetRepository, projectRepo *repository.ProjectRepository, userRepo *repository.UserRepository) *TimerService {
+	return &TimerService{
+		repo:          repo,
+		timesheetRepo: timesheetRepo,
+		projectRepo:   projectRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// checkStartUnlocked fails when the week of now or the user's running entry,
+// which starting a timer stops at now, is locked.
+func (s *TimerService) checkStartUnlocked(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	from := now
+
+	running, err := s.repo.GetRunningTimeEntry(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil {
+		from = running.StartedAt
+	}
+
+	loc, err := weekLocation(ctx, s.userRepo, userID)
+	if err != nil {
+		return err
+	}
+
+	return checkUnlocked(ctx, s.timesheetRepo, userID, loc, from, now)
+}
+
+// Location resolves the timezone used to present the user's timer. The
+// explicit timezone wins over the one stored on the user.
+func (s *TimerService) Location(user *entity.User, timezone string) (*time.Location, error) {
+	if timezone == "" {
+		timezone = user.Timezone
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrTimerInvalidTimezone
+	}
+
+	return loc, nil
+}
+
+// StartTimer starts a new running entry at the current time, stopping the
+// user's previous timer if there is one. Timer timestamps are kept in UTC.
+func (s *TimerService) StartTimer(ctx context.Context, value *entity.TimeEntry) (*entity.TimeEntry, error) {
+	value.StartedAt = time.Now().UTC()
+	value.EndedAt = nil
+	value.Duration = 0
+
+	if err := s.checkStartUnlocked(ctx, value.UserID, value.StartedAt); err != nil {
+		return nil, err
+	}
+
+	if err := checkProjectAccess(ctx, s.projectRepo, value.ProjectID, value.UserID); err != nil {
+		return nil, err
+	}
+
+	record, err := s.repo.StartTimeEntry(ctx, value)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrTimerAlreadyRunning
+		}
+
+		return nil, overlapError(err)
+	}
+
+	return record, nil
+}
+
+func (s *TimerService) StopTimer(ctx context.Context, userID uuid.UUID) (*entity.TimeEntry, error) {
+	now := time.Now().UTC()
+
+	running, err := s.repo.GetRunningTimeEntry(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTimerNotRunning
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := weekLocation(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUnlocked(ctx, s.timesheetRepo, userID, loc, running.StartedAt, now); err != nil {
+		return nil, err
+	}
+
+	record, err := s.repo.StopRunningTimeEntry(ctx, userID, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTimerNotRunning
+	}
+
+	return record, err
+}
+
+func (s *TimerService) CurrentTimer(ctx context.Context, userID uuid.UUID) (*entity.TimeEntry, error) {
+	record, err := s.repo.GetRunningTimeEntry(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTimerNotRunning
+	}
+
+	return record, err
+}
+
+// ResumeTimer starts a new timer carrying over the project, description and
+// billable flag of the user's most recently stopped entry.
+func (s *TimerService) ResumeTimer(ctx context.Context, userID uuid.UUID) (*entity.TimeEntry, error) {
+	latest, err := s.repo.GetLatestTimeEntry(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTimerNothingToResume
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.StartTimer(ctx, &entity.TimeEntry{
+		UserID:      latest.UserID,
+		ProjectID:   latest.ProjectID,
+		Description: latest.Description,
+		IsBillable:  latest.IsBillable,
+	})
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"gofi/pkg/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTimesheetInvalidWeek       = apperror.New(apperror.ErrValidation, "year and week do not form a valid ISO week")
+	ErrTimesheetInvalidTransition = apperror.New(apperror.ErrConflict, "timesheet cannot move to the requested status")
+	ErrTimesheetCommentRequired   = apperror.New(apperror.ErrValidation, "a comment is required to reject a timesheet")
+	ErrTimesheetNotApprover       = apperror.New(apperror.ErrForbidden, "reviewing timesheets requires the timesheet:approve permission")
+	ErrTimesheetSelfReview        = apperror.New(apperror.ErrForbidden, "a timesheet cannot be reviewed by its owner")
+	ErrTimesheetForbidden         = apperror.New(apperror.ErrForbidden, "timesheet belongs to another user")
+	ErrTimesheetTimerRunning      = apperror.New(apperror.ErrConflict, "stop the running timer before submitting its week")
+)
+
+type TimesheetService struct {
+	repo           *repository.TimesheetRepository
+	timeEntryRepo  *repository.TimeEntryRepository
+	permissionRepo *repository.PermissionRepository
+	userRepo       *repository.UserRepository
+}
+
+func NewTimesheetService(repo *repository.TimesheetRepository, timeEntryRepo *repository.TimeEntryRepository, permissionRepo *repository.PermissionRepository, userRepo *repository.UserRepository) *TimesheetService {
+	return &TimesheetService{
+		repo:           repo,
+		timeEntryRepo:  timeEntryRepo,
+		permissionRepo: permissionRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// isApprover reports whether the user's role may review timesheets.
+func (s *TimesheetService) isApprover(ctx context.Context, user *entity.User) (bool, error) {
+	return s.permissionRepo.HasPermission(ctx, user.RoleID, constant.PermissionTimesheetApprove)
+}
+
+// isoWeekStart returns midnight of the Monday that opens the given ISO week
+// in the location.
+func isoWeekStart(year int, week int, loc *time.Location) time.Time {
+	// January 4th always falls in the first ISO week of its year
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	offset := (int(jan4.Weekday()) + 6) % 7
+
+	return jan4.AddDate(0, 0, -offset+(week-1)*7)
+}
+
+// SubmitTimesheet moves the user's timesheet for the given ISO week to
+// submitted, creating it on the fly when the week has no timesheet yet. A
+// timer running since that week would no longer be stoppable, so it has to be
+// stopped first.
+func (s *TimesheetService) SubmitTimesheet(ctx context.Context, userID uuid.UUID, year int, week int) (*entity.Timesheet, error) {
+	loc, err := weekLocation(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := isoWeekStart(year, week, loc)
+	if y, w := start.ISOWeek(); y != year || w != week {
+		return nil, ErrTimesheetInvalidWeek
+	}
+
+	running, err := s.timeEntryRepo.GetRunningTimeEntry(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil && running.StartedAt.Before(start.AddDate(0, 0, 7)) {
+		return nil, ErrTimesheetTimerRunning
+	}
+
+	now := time.Now().UTC()
+
+	record, err := s.repo.GetTimesheetByWeek(ctx, userID, year, week)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.repo.CreateTimesheet(ctx, &entity.Timesheet{
+			UserID:      userID,
+			Year:        year,
+			Week:        week,
+			Status:      constant.TimesheetStatusSubmitted,
+			SubmittedAt: &now,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Status != constant.TimesheetStatusDraft && record.Status != constant.TimesheetStatusRejected {
+		return nil, ErrTimesheetInvalidTransition
+	}
+
+	record.Status = constant.TimesheetStatusSubmitted
+	record.SubmittedAt = &now
+	record.UpdatedAt = now
+
+	return s.repo.UpdateTimesheet(ctx, record)
+}
+
+// GetTimesheet returns the timesheet to its owner or to an approver.
+func (s *TimesheetService) GetTimesheet(ctx context.Context, id uuid.UUID, user *entity.User) (*entity.Timesheet, error) {
+	record, err := s.repo.GetTimesheet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.UserID == user.ID {
+		return record, nil
+	}
+
+	approver, err := s.isApprover(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if !approver {
+		return nil, ErrTimesheetForbidden
+	}
+
+	return record, nil
+}
+
+// ListTimesheets lists every timesheet for approvers and only the user's own
+// timesheets for everyone else.
+func (s *TimesheetService) ListTimesheets(ctx context.Context, user *entity.User, opts entity.QueryOptions) ([]entity.Timesheet, int, error) {
+	approver, err := s.isApprover(ctx, user)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if approver {
+		return s.repo.ListTimesheets(ctx, opts)
+	}
+
+	return s.repo.ListTimesheetsByUser(ctx, user.ID, opts)
+}
+
+// review checks the reviewer may act on the submitted timesheet and records
+// the decision.
+func (s *TimesheetService) review(ctx context.Context, id uuid.UUID, reviewer *entity.User, status string, comment *string) (*entity.Timesheet, error) {
+	approver, err := s.isApprover(ctx, reviewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if !approver {
+		return nil, ErrTimesheetNotApprover
+	}
+
+	record, err := s.repo.GetTimesheet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.UserID == reviewer.ID {
+		return nil, ErrTimesheetSelfReview
+	}
+
+	if record.Status != constant.TimesheetStatusSubmitted {
+		return nil, ErrTimesheetInvalidTransition
+	}
+
+	now := time.Now().UTC()
+
+	record.Status = status
+	record.ReviewedAt = &now
+	record.ReviewerID = &reviewer.ID
+	record.Comment = comment
+	record.UpdatedAt = now
+
+	return s.repo.UpdateTimesheet(ctx, record)
+}
+
+func (s *TimesheetService) ApproveTimesheet(ctx context.Context, id uuid.UUID, reviewer *entity.User, comment string) (*entity.Timesheet, error) {
+	var note *string
+	if comment != "" {
+		note = &comment
+	}
+
+	return s.review(ctx, id, reviewer, constant.TimesheetStatusApproved, note)
+}
+
+func (s *TimesheetService) RejectTimesheet(ctx context.Context, id uuid.UUID, reviewer *entity.User, comment string) (*entity.Timesheet, error) {
+	if comment == "" {
+		return nil, ErrTimesheetCommentRequired
+	}
+
+	return s.review(ctx, id, reviewer, constant.TimesheetStatusRejected, &comment)
+}
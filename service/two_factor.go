@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"gofi/pkg/totp"
+	"gofi/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = apperror.New(apperror.ErrConflict, "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = apperror.New(apperror.ErrConflict, "two-factor authentication has not been set up")
+	ErrTwoFactorInvalidCode    = apperror.New(apperror.ErrValidation, "verification code is invalid or has already been used")
+	ErrTwoFactorRequired       = apperror.New(apperror.ErrForbidden, "two-factor authentication is required for your role")
+)
+
+// recoveryCodeCount is how many recovery codes a user gets at a time.
+const recoveryCodeCount = 10
+
+type TwoFactorService struct {
+	repo     *repository.TwoFactorRepository
+	roleRepo *repository.RoleRepository
+	issuer   string
+}
+
+// NewTwoFactorService builds the service. issuer is the name authenticator
+// apps show next to the account.
+func NewTwoFactorService(repo *repository.TwoFactorRepository, roleRepo *repository.RoleRepository, issuer string) *TwoFactorService {
+	return &TwoFactorService{
+		repo:     repo,
+		roleRepo: roleRepo,
+		issuer:   issuer,
+	}
+}
+
+// normalizeRecoveryCode makes recovery codes case and dash insensitive.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// generateRecoveryCodes returns the plain codes to show the user once and the
+// hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		token, err := utils.GenerateToken(5)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, token[:5]+"-"+token[5:])
+		hashes = append(hashes, utils.HashToken(normalizeRecoveryCode(token)))
+	}
+
+	return codes, hashes, nil
+}
+
+// isTOTPCode tells a 6 digit authenticator code from a recovery code.
+func isTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	return len(code) == 6 && strings.Trim(code, "0123456789") == ""
+}
+
+// verifyTwoFactorCode accepts either a current authenticator code, at most
+// once per time step, or an unused recovery code of the user.
+func verifyTwoFactorCode(ctx context.Context, repo *repository.TwoFactorRepository, userID uuid.UUID, code string) error {
+	if !isTOTPCode(code) {
+		used, err := repo.UseRecoveryCode(ctx, userID, utils.HashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return err
+		}
+
+		if !used {
+			return ErrTwoFactorInvalidCode
+		}
+
+		return nil
+	}
+
+	record, err := repo.GetTwoFactor(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTwoFactorNotEnrolled
+	}
+	if err != nil {
+		return err
+	}
+
+	if record.ConfirmedAt == nil {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := totp.Validate(record.Secret, code, time.Now())
+	if !ok {
+		return ErrTwoFactorInvalidCode
+	}
+
+	used, err := repo.UseTwoFactorStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		return ErrTwoFactorInvalidCode
+	}
+
+	return nil
+}
+
+// Enroll generates a new secret for the user. Two-factor authentication only
+// becomes active once a code from it is confirmed.
+func (s *TwoFactorService) Enroll(ctx context.Context, user *entity.User) (*entity.TwoFactorEnrollRes, error) {
+	enabled, err := s.repo.IsTwoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.SaveTwoFactor(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &entity.TwoFactorEnrollRes{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm activates two-factor authentication with a code from the enrolled
+// secret and returns the user's recovery codes.
+func (s *TwoFactorService) Confirm(ctx context.Context, user *entity.User, code string) ([]string, error) {
+	record, err := s.repo.GetTwoFactor(ctx, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if record.ConfirmedAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(record.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrTwoFactorInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ConfirmTwoFactor(ctx, user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, user *entity.User, code string) ([]string, error) {
+	if err := verifyTwoFactorCode(ctx, s.repo, user.ID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off, unless the user's role
+// requires it.
+func (s *TwoFactorService) Disable(ctx context.Context, user *entity.User, code string) error {
+	role, err := s.roleRepo.GetRole(ctx, user.RoleID)
+	if err != nil {
+		return err
+	}
+
+	if role.Require2FA {
+		return ErrTwoFactorRequired
+	}
+
+	if err := verifyTwoFactorCode(ctx, s.repo, user.ID, code); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteTwoFactor(ctx, user.ID)
+}
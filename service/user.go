@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gofi/database/entity"
+	"gofi/database/repository"
+	"gofi/pkg/apperror"
+	"gofi/pkg/constant"
+	"gofi/pkg/mailer"
+	"gofi/pkg/utils"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/masb0ymas/go-utils/argon2"
+)
+
+var (
+	ErrUserEmailTaken        = apperror.New(apperror.ErrConflict, "email is already registered")
+	ErrUserPasswordRequired  = apperror.New(apperror.ErrValidation, "password is required")
+	ErrUserInvalidTimezone   = apperror.New(apperror.ErrValidation, "timezone is not a valid IANA time zone")
+	ErrUserInvalidVerifyLink = apperror.New(apperror.ErrValidation, "verification link is invalid or has already been used")
+	ErrUserInUse             = apperror.New(apperror.ErrConflict, "user still has time entries, timesheets or projects")
+	ErrUserRoleForbidden     = apperror.New(apperror.ErrForbidden, "role grants permissions you do not have")
+)
+
+type UserService struct {
+	repo           *repository.UserRepository
+	lockoutRepo    *repository.AccountLockoutRepository
+	permissionRepo *repository.PermissionRepository
+	mailer         mailer.Mailer
+	verifyURL      string
+}
+
+// NewUserService builds the service. verifyURL is the address of the verify
+// email endpoint; the token is appended to it as the "token" query parameter.
+func NewUserService(repo *repository.UserRepository, lockoutRepo *repository.AccountLockoutRepository, permissionRepo *repository.PermissionRepository, mailer mailer.Mailer, verifyURL string) *UserService {
+	return &UserService{
+		repo:           repo,
+		lockoutRepo:    lockoutRepo,
+		permissionRepo: permissionRepo,
+		mailer:         mailer,
+		verifyURL:      verifyURL,
+	}
+}
+
+// prepare validates the timezone and hashes the plain text password when one
+// is given.
+func (s *UserService) prepare(value *entity.User, password string) error {
+	if value.Timezone == "" {
+		value.Timezone = constant.DefaultTimezone
+	}
+
+	if _, err := time.LoadLocation(value.Timezone); err != nil {
+		return ErrUserInvalidTimezone
+	}
+
+	if password != "" {
+		value.Password = argon2.Generate(password)
+	}
+
+	return nil
+}
+
+// translate turns a unique violation on the email index into ErrUserEmailTaken
+// and a foreign key violation into ErrUserInUse.
+func (s *UserService) translate(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrUserEmailTaken
+	}
+
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrUserInUse
+	}
+
+	return err
+}
+
+// CheckRole makes sure a caller holding the granted permissions may hand out
+// the role: every permission of the role must be one of theirs, so nobody can
+// give a user, themselves included, more than they have.
+func (s *UserService) CheckRole(ctx context.Context, granted []string, roleID uuid.UUID) error {
+	permissions, err := s.permissionRepo.ListPermissionNamesByRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if !slices.Contains(granted, permission) {
+			return fmt.Errorf("%w: %q", ErrUserRoleForbidden, permission)
+		}
+	}
+
+	return nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, value *entity.User, password string) (*entity.User, error) {
+	if password == "" {
+		return nil, ErrUserPasswordRequired
+	}
+
+	if err := s.prepare(value, password); err != nil {
+		return nil, err
+	}
+
+	record, err := s.repo.CreateUser(ctx, value)
+	if err != nil {
+		return nil, s.translate(err)
+	}
+
+	return record, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return s.repo.GetUser(ctx, id)
+}
+
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return s.repo.GetUserByEmail(ctx, email)
+}
+
+func (s *UserService) ListUsers(ctx context.Context, withDeleted bool, opts entity.QueryOptions) ([]entity.User, int, error) {
+	return s.repo.ListUsers(ctx, withDeleted, opts)
+}
+
+// UpdateUser saves the user, replacing the password only when a new one is given.
+func (s *UserService) UpdateUser(ctx context.Context, value *entity.User, password string) (*entity.User, error) {
+	if err := s.prepare(value, password); err != nil {
+		return nil, err
+	}
+
+	record, err := s.repo.UpdateUser(ctx, value)
+	if err != nil {
+		return nil, s.translate(err)
+	}
+
+	return record, nil
+}
+
+// SetPassword replaces the password of the user and signs them out everywhere.
+func (s *UserService) SetPassword(ctx context.Context, id uuid.UUID, password string) error {
+	if password == "" {
+		return ErrUserPasswordRequired
+	}
+
+	return s.repo.SetPassword(ctx, id, argon2.Generate(password))
+}
+
+// DeleteUser soft deletes the user and signs them out everywhere.
+func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteUser(ctx, id)
+}
+
+// RestoreUser brings back a soft deleted user, unless their email has been
+// registered again in the meantime.
+func (s *UserService) RestoreUser(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	record, err := s.repo.RestoreUser(ctx, id)
+	if err != nil {
+		return nil, s.translate(err)
+	}
+
+	return record, nil
+}
+
+func (s *UserService) ForceDeleteUser(ctx context.Context, id uuid.UUID) error {
+	return s.translate(s.repo.ForceDeleteUser(ctx, id))
+}
+
+// SignUp registers an inactive user with the default role and emails them a
+// link to verify their address.
+func (s *UserService) SignUp(ctx context.Context, value *entity.User, password string) (*entity.User, error) {
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	value.RoleID = uuid.MustParse(constant.RoleUser)
+	value.IsActive = false
+	value.IsBlocked = false
+	value.TokenVerify = &token
+
+	record, err := s.CreateUser(ctx, value, password)
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nPlease verify your email address by opening the link below:\n\n%s?token=%s\n", record.Fullname, s.verifyURL, token)
+
+	// the account exists at this point, a failed delivery must not fail the sign-up
+	if err := s.mailer.Send(ctx, record.Email, "Verify your email address", body); err != nil {
+		log.Printf("error sending verification email to %s: %v", record.Email, err)
+	}
+
+	return record, nil
+}
+
+// VerifyEmail activates the user owning the token. Each token can be used once.
+func (s *UserService) VerifyEmail(ctx context.Context, token string) (*entity.User, error) {
+	if token == "" {
+		return nil, ErrUserInvalidVerifyLink
+	}
+
+	record, err := s.repo.GetUserByTokenVerify(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserInvalidVerifyLink
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record.IsActive = true
+	record.TokenVerify = nil
+	record.UpdatedAt = time.Now()
+
+	return s.repo.UpdateUser(ctx, record)
+}
+
+// UnlockUser lifts a sign-in lockout of the user on behalf of admin.
+func (s *UserService) UnlockUser(ctx context.Context, id uuid.UUID, admin *entity.User) (*entity.User, error) {
+	if _, err := s.repo.GetUser(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.lockoutRepo.UnlockUser(ctx, id, admin.ID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetUser(ctx, id)
+}
+
+// ListAccountLockouts returns the lockout history of the user, newest first.
+func (s *UserService) ListAccountLockouts(ctx context.Context, id uuid.UUID) ([]entity.AccountLockout, error) {
+	if _, err := s.repo.GetUser(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.lockoutRepo.ListAccountLockouts(ctx, id)
+}